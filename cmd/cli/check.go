@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/samart/terraform-schema-generator/pkg/generator"
+	"github.com/samart/terraform-schema-generator/pkg/parser"
+	"github.com/samart/terraform-schema-generator/pkg/validator"
+)
+
+// checkOptions holds the flags of the check command
+type checkOptions struct {
+	dir       string
+	format    string
+	installed bool
+}
+
+// newCheckCommand creates the check subcommand, which checks the module
+// calls of a configuration against the variables of the modules they call
+func newCheckCommand() *cobra.Command {
+	opts := &checkOptions{}
+	cmd := &cobra.Command{
+		Use:   "check --dir MODULE",
+		Short: "Check module calls against the variables of the called modules",
+		Long: `Loads the local modules a Terraform configuration calls, recursively, and
+checks the arguments of every module block against the variables of the
+module it calls. Reports required variables that are not set, arguments
+the module does not declare, and literal values that do not match a
+variable's type or violate its allowed values or bounds.
+
+With --installed, registry and git modules that terraform init downloaded
+are checked too, found through .terraform/modules/modules.json. Nothing is
+fetched over the network.
+
+Missing module directories and modules that call themselves are reported
+too. Exits with a non-zero status when any error is found.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCheck(opts)
+		},
+		SilenceUsage: true,
+	}
+
+	cmd.Flags().StringVarP(&opts.dir, "dir", "d", "", "Directory containing the root Terraform module")
+	cmd.Flags().StringVar(&opts.format, "format", formatText, "Output format: text or json")
+	cmd.Flags().BoolVar(&opts.installed, "installed", false, "Also check modules installed by terraform init")
+	_ = cmd.MarkFlagRequired("dir")
+
+	return cmd
+}
+
+// checkReport is the outcome of a check run
+type checkReport struct {
+	Valid       bool               `json:"valid"`
+	Diagnostics parser.Diagnostics `json:"diagnostics"`
+}
+
+func runCheck(opts *checkOptions) error {
+	if opts.format != formatText && opts.format != formatJSON {
+		return fmt.Errorf("invalid format %q: must be %s or %s", opts.format, formatText, formatJSON)
+	}
+	if err := checkInput(generateOptions{inputDir: opts.dir}); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Problems loading the modules are part of the report, so parsing is
+	// never strict here
+	gen := generator.New(generator.WithModules(true), generator.WithInstalledModules(opts.installed)).
+		FromDirectory(opts.dir).
+		Parse()
+	result, err := gen.ParseResult()
+	if err != nil {
+		return fmt.Errorf("parsing failed: %w", err)
+	}
+
+	callDiags, err := validator.ValidateModuleCalls(result)
+	if err != nil {
+		return err
+	}
+	diags := append(append(parser.Diagnostics{}, gen.Diagnostics()...), callDiags...)
+
+	report := checkReport{Valid: !diags.HasErrors(), Diagnostics: diags}
+
+	if opts.format == formatJSON {
+		if err := writeJSONReport(os.Stdout, report); err != nil {
+			return err
+		}
+	} else {
+		if err := parser.WriteDiagnostics(os.Stdout, diags); err != nil {
+			return err
+		}
+		if report.Valid {
+			calls := 0
+			result.WalkModules(func(key string, module *parser.Module) {
+				if module.Config != nil {
+					calls++
+				}
+			})
+			fmt.Fprintf(os.Stderr, "✓ %d module call(s) checked\n", calls)
+		}
+	}
+
+	if !report.Valid {
+		return fmt.Errorf("found %d error(s) in module calls", len(diags.Errors()))
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupCheckCommand creates a fresh check command, whose flags start at their defaults
+func setupCheckCommand() *cobra.Command {
+	return newCheckCommand()
+}
+
+func TestCLI_Check(t *testing.T) {
+	writeNetwork := func(t *testing.T, root string) string {
+		dir := filepath.Join(root, "modules", "network")
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "variables.tf"), []byte(`
+variable "cidr" {
+  type = string
+}
+
+variable "azs" {
+  type    = number
+  default = 2
+}
+`), 0644))
+		return root
+	}
+
+	t.Run("valid calls", func(t *testing.T) {
+		root := writeNetwork(t, writeModule(t, `
+module "network" {
+  source = "./modules/network"
+  cidr   = "10.0.0.0/16"
+}
+`))
+
+		cmd := setupCheckCommand()
+		stdout, stderr, err := executeCommand(cmd, "--dir", root)
+		require.NoError(t, err)
+		assert.Empty(t, stdout)
+		assert.Contains(t, stderr, "1 module call(s) checked")
+	})
+
+	t.Run("reports problems", func(t *testing.T) {
+		root := writeNetwork(t, writeModule(t, `
+module "network" {
+  source = "./modules/network"
+  azs    = "two"
+}
+
+module "dns" {
+  source = "./modules/dns"
+}
+`))
+
+		cmd := setupCheckCommand()
+		stdout, _, err := executeCommand(cmd, "-d", root)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "found 3 error(s)")
+		assert.Contains(t, stdout, "Error: Unreadable module directory")
+		assert.Contains(t, stdout, "Error: Missing required argument")
+		assert.Contains(t, stdout, "Error: Invalid value for input variable")
+		assert.Contains(t, stdout, "module.network.var.azs: a number is required.")
+	})
+
+	t.Run("JSON report", func(t *testing.T) {
+		root := writeNetwork(t, writeModule(t, `
+module "network" {
+  source = "./modules/network"
+  cidr   = "10.0.0.0/16"
+  region = "eu-west-1"
+}
+`))
+
+		cmd := setupCheckCommand()
+		stdout, _, err := executeCommand(cmd, "-d", root, "--format", "json")
+		require.Error(t, err)
+
+		var report checkReport
+		require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+		assert.False(t, report.Valid)
+		require.Len(t, report.Diagnostics, 1)
+		assert.Equal(t, "Unsupported argument", report.Diagnostics[0].Summary)
+		assert.Equal(t, 5, report.Diagnostics[0].Subject.Start.Line)
+	})
+
+	t.Run("installed modules", func(t *testing.T) {
+		root := writeModule(t, `
+module "vpc" {
+  source = "terraform-aws-modules/vpc/aws"
+}
+`)
+		installed := filepath.Join(root, ".terraform", "modules", "vpc")
+		require.NoError(t, os.MkdirAll(installed, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(installed, "variables.tf"), []byte("variable \"cidr\" {\n  type = string\n}\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(root, ".terraform", "modules", "modules.json"),
+			[]byte(`{"Modules":[{"Key":"vpc","Source":"registry.terraform.io/terraform-aws-modules/vpc/aws","Dir":".terraform/modules/vpc"}]}`), 0644))
+
+		cmd := setupCheckCommand()
+		_, _, err := executeCommand(cmd, "-d", root)
+		require.NoError(t, err)
+
+		cmd = setupCheckCommand()
+		stdout, _, err := executeCommand(cmd, "-d", root, "--installed")
+		require.Error(t, err)
+		assert.Contains(t, stdout, `The argument "cidr" is required by module.vpc`)
+	})
+}
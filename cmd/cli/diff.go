@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/samart/terraform-schema-generator/pkg/converter"
+	"github.com/samart/terraform-schema-generator/pkg/diff"
+)
+
+// diffOptions holds the flags of the diff command
+type diffOptions struct {
+	format         string
+	failOnBreaking bool
+}
+
+// newDiffCommand creates the diff subcommand, which reports the changes
+// between two versions of a module and whether they break its consumers
+func newDiffCommand() *cobra.Command {
+	opts := &diffOptions{}
+	cmd := &cobra.Command{
+		Use:   "diff OLD NEW",
+		Short: "Detect breaking changes between two module versions",
+		Long: `Compares two versions of a Terraform module and classifies each change
+as breaking or non-breaking, then suggests a semantic version bump.
+
+OLD and NEW are either module directories or schema files generated by
+this tool. Outputs are only compared between directories, since schemas
+do not describe them.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDiff(opts, args)
+		},
+		SilenceUsage: true,
+	}
+
+	cmd.Flags().StringVar(&opts.format, "format", formatText, "Output format: text or json")
+	cmd.Flags().BoolVar(&opts.failOnBreaking, "fail-on-breaking", false, "Exit with a non-zero status when any change is breaking")
+
+	return cmd
+}
+
+// diffReport is the JSON form of a diff run
+type diffReport struct {
+	Breaking      bool          `json:"breaking"`
+	SuggestedBump diff.Bump     `json:"suggested_bump"`
+	Changes       []diff.Change `json:"changes"`
+}
+
+func runDiff(opts *diffOptions, args []string) error {
+	if opts.format != formatText && opts.format != formatJSON {
+		return fmt.Errorf("invalid format %q: must be %s or %s", opts.format, formatText, formatJSON)
+	}
+
+	report, err := compareArgs(args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	if opts.format == formatJSON {
+		err = writeJSONReport(os.Stdout, diffReport{
+			Breaking:      report.HasBreaking(),
+			SuggestedBump: report.SuggestedBump(),
+			Changes:       report.Changes,
+		})
+	} else {
+		err = diff.WriteReport(os.Stdout, report)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	if opts.failOnBreaking && report.HasBreaking() {
+		return fmt.Errorf("%d breaking change(s) found", len(report.Breaking()))
+	}
+	return nil
+}
+
+// compareArgs compares two module directories, or two schema files
+func compareArgs(oldPath, newPath string) (*diff.Report, error) {
+	oldInfo, err := os.Stat(oldPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot access %s: %w", oldPath, err)
+	}
+	newInfo, err := os.Stat(newPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot access %s: %w", newPath, err)
+	}
+
+	switch {
+	case oldInfo.IsDir() && newInfo.IsDir():
+		return diff.CompareDirectories(oldPath, newPath)
+	case !oldInfo.IsDir() && !newInfo.IsDir():
+		oldSchema, err := readSchemaFile(oldPath)
+		if err != nil {
+			return nil, err
+		}
+		newSchema, err := readSchemaFile(newPath)
+		if err != nil {
+			return nil, err
+		}
+		return diff.CompareSchemas(oldSchema, newSchema), nil
+	}
+	return nil, fmt.Errorf("cannot compare %s with %s: both must be directories or both schema files", oldPath, newPath)
+}
+
+// readSchemaFile reads a schema generated by this tool
+func readSchemaFile(path string) (*converter.JSONSchema7, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	schema, err := converter.ParseJSONSchema7(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return schema, nil
+}
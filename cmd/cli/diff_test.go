@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/samart/terraform-schema-generator/pkg/diff"
+)
+
+// setupDiffCommand creates a fresh diff command, whose flags start at their defaults
+func setupDiffCommand() *cobra.Command {
+	return newDiffCommand()
+}
+
+// writeModule writes a single-file module and returns its directory
+func writeModule(t *testing.T, content string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(content), 0644))
+	return dir
+}
+
+func TestCLI_Diff(t *testing.T) {
+	oldDir := writeModule(t, `
+variable "name" {
+  type = string
+}
+
+output "id" {
+  value = "x"
+}
+`)
+	newDir := writeModule(t, `
+variable "name" {
+  type = string
+}
+
+variable "region" {
+  type = string
+}
+`)
+	optionalDir := writeModule(t, `
+variable "name" {
+  type = string
+}
+
+variable "tags" {
+  type    = map(string)
+  default = {}
+}
+
+output "id" {
+  value = "x"
+}
+`)
+
+	t.Run("text report", func(t *testing.T) {
+		cmd := setupDiffCommand()
+		stdout, _, err := executeCommand(cmd, oldDir, newDir)
+
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "✗ var.region: required variable added")
+		assert.Contains(t, stdout, "✗ output.id: output removed")
+		assert.Contains(t, stdout, "Suggested version bump: major")
+	})
+
+	t.Run("fail on breaking", func(t *testing.T) {
+		cmd := setupDiffCommand()
+		_, _, err := executeCommand(cmd, "--fail-on-breaking", oldDir, newDir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "2 breaking change(s) found")
+
+		cmd = setupDiffCommand()
+		_, _, err = executeCommand(cmd, "--fail-on-breaking", oldDir, optionalDir)
+		assert.NoError(t, err)
+	})
+
+	t.Run("JSON report", func(t *testing.T) {
+		cmd := setupDiffCommand()
+		stdout, _, err := executeCommand(cmd, "--format", "json", oldDir, optionalDir)
+		require.NoError(t, err)
+
+		var report diffReport
+		require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+		assert.False(t, report.Breaking)
+		assert.Equal(t, diff.BumpMinor, report.SuggestedBump)
+		require.Len(t, report.Changes, 1)
+		assert.Equal(t, diff.VariableAdded, report.Changes[0].Kind)
+	})
+
+	t.Run("schema files", func(t *testing.T) {
+		schemas := t.TempDir()
+		oldSchema := filepath.Join(schemas, "old.json")
+		newSchema := filepath.Join(schemas, "new.json")
+		for path, dir := range map[string]string{oldSchema: oldDir, newSchema: newDir} {
+			cmd := setupTestCommand()
+			_, _, err := executeCommand(cmd, "-d", dir, "-o", path)
+			require.NoError(t, err)
+		}
+
+		cmd := setupDiffCommand()
+		stdout, _, err := executeCommand(cmd, oldSchema, newSchema)
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "✗ var.region: required variable added")
+		assert.NotContains(t, stdout, "output.id", "schemas do not describe outputs")
+	})
+
+	t.Run("mixed arguments", func(t *testing.T) {
+		cmd := setupDiffCommand()
+		_, _, err := executeCommand(cmd, oldDir, filepath.Join(oldDir, "main.tf"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "both must be directories or both schema files")
+	})
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/samart/terraform-schema-generator/pkg/converter"
+	"github.com/samart/terraform-schema-generator/pkg/docs"
+	"github.com/samart/terraform-schema-generator/pkg/generator"
+	"github.com/samart/terraform-schema-generator/pkg/parser"
+)
+
+// docsOptions holds the flags of the docs command
+type docsOptions struct {
+	dir    string
+	readme string
+	check  bool
+	strict bool
+}
+
+// newDocsCommand creates the docs subcommand, which renders Markdown
+// documentation of a module's inputs and outputs
+func newDocsCommand() *cobra.Command {
+	opts := &docsOptions{}
+	cmd := &cobra.Command{
+		Use:   "docs --dir MODULE",
+		Short: "Generate Markdown documentation for a Terraform module",
+		Long: `Renders tables of a module's requirements, modules, resources, inputs and
+outputs as Markdown. Inputs list their type, default, whether they are
+required or sensitive, and the constraints and error messages of their
+validation rules.
+
+With --readme, the tables replace the content between the
+` + docs.BeginMarker + ` and ` + docs.EndMarker + ` comments of an existing
+README. Add --check to fail instead when the README is out of date.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDocs(opts)
+		},
+		SilenceUsage: true,
+	}
+
+	cmd.Flags().StringVarP(&opts.dir, "dir", "d", "", "Directory containing the Terraform module")
+	cmd.Flags().StringVar(&opts.readme, "readme", "", "README file to inject the documentation into")
+	cmd.Flags().BoolVar(&opts.check, "check", false, "Fail when the README is out of date instead of updating it")
+	cmd.Flags().BoolVar(&opts.strict, "strict", true, "Fail on any parse error instead of skipping the affected files")
+	_ = cmd.MarkFlagRequired("dir")
+
+	return cmd
+}
+
+func runDocs(opts *docsOptions) error {
+	if opts.check && opts.readme == "" {
+		return fmt.Errorf("--check requires --readme")
+	}
+	if err := checkInput(generateOptions{inputDir: opts.dir}); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	result, schema, err := parseModule(opts.dir, opts.strict)
+	if err != nil {
+		return err
+	}
+	content := docs.Markdown(result, schema)
+
+	if opts.readme == "" {
+		_, err := fmt.Fprintln(os.Stdout, string(content))
+		return err
+	}
+
+	readme, err := os.ReadFile(opts.readme)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", opts.readme, err)
+	}
+	updated, err := docs.Inject(readme, content)
+	if err != nil {
+		return fmt.Errorf("%s: %w", opts.readme, err)
+	}
+
+	if opts.check {
+		if !bytes.Equal(readme, updated) {
+			return fmt.Errorf("%s is out of date: run the docs command with --readme to update it", opts.readme)
+		}
+		fmt.Fprintf(os.Stderr, "✓ %s is up to date\n", opts.readme)
+		return nil
+	}
+
+	if err := os.WriteFile(opts.readme, updated, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", opts.readme, err)
+	}
+	return nil
+}
+
+// parseModule parses the module in dir and converts its variables,
+// returning a nil schema when the module has none
+func parseModule(dir string, strict bool) (*parser.ParseResult, *converter.JSONSchema7, error) {
+	gen := generator.New(generator.WithStrict(strict)).FromDirectory(dir).Parse()
+	if diags := gen.Diagnostics(); len(diags) > 0 {
+		if err := parser.WriteDiagnostics(os.Stderr, diags); err != nil {
+			return nil, nil, fmt.Errorf("failed to write diagnostics: %w", err)
+		}
+	}
+
+	result, err := gen.ParseResult()
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing failed: %w", err)
+	}
+	if len(result.Variables) == 0 {
+		return result, nil, nil
+	}
+
+	schema, err := gen.Convert().Schema()
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, schema, nil
+}
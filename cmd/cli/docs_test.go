@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/samart/terraform-schema-generator/pkg/docs"
+)
+
+// setupDocsCommand creates a fresh docs command, whose flags start at their defaults
+func setupDocsCommand() *cobra.Command {
+	return newDocsCommand()
+}
+
+func TestCLI_Docs(t *testing.T) {
+	moduleDir := writeModule(t, `
+variable "environment" {
+  type        = string
+  description = "Deployment environment"
+  validation {
+    condition     = contains(["dev", "prod"], var.environment)
+    error_message = "Unknown environment."
+  }
+}
+
+output "id" {
+  value = "x"
+}
+`)
+
+	t.Run("prints to stdout", func(t *testing.T) {
+		cmd := setupDocsCommand()
+		stdout, _, err := executeCommand(cmd, "--dir", moduleDir)
+
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "## Inputs")
+		assert.Contains(t, stdout, "| `environment` | Deployment environment | `string` | n/a | yes | no | one of `\"dev\"`, `\"prod\"`<br>Unknown environment. |")
+		assert.Contains(t, stdout, "## Outputs")
+	})
+
+	t.Run("injects into README and checks it", func(t *testing.T) {
+		readme := filepath.Join(t.TempDir(), "README.md")
+		require.NoError(t, os.WriteFile(readme, []byte("# Module\n\n"+docs.BeginMarker+"\n"+docs.EndMarker+"\n"), 0644))
+
+		cmd := setupDocsCommand()
+		_, _, err := executeCommand(cmd, "-d", moduleDir, "--readme", readme, "--check")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is out of date")
+
+		cmd = setupDocsCommand()
+		_, _, err = executeCommand(cmd, "-d", moduleDir, "--readme", readme)
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(readme)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "# Module\n\n"+docs.BeginMarker+"\n## Inputs\n")
+		assert.Contains(t, string(content), "| `id` |  | no |\n"+docs.EndMarker+"\n")
+
+		cmd = setupDocsCommand()
+		_, stderr, err := executeCommand(cmd, "-d", moduleDir, "--readme", readme, "--check")
+		require.NoError(t, err)
+		assert.Contains(t, stderr, "is up to date")
+	})
+
+	t.Run("README without markers", func(t *testing.T) {
+		readme := filepath.Join(t.TempDir(), "README.md")
+		require.NoError(t, os.WriteFile(readme, []byte("# Module\n"), 0644))
+
+		cmd := setupDocsCommand()
+		_, _, err := executeCommand(cmd, "-d", moduleDir, "--readme", readme)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), docs.BeginMarker)
+	})
+
+	t.Run("check requires a README", func(t *testing.T) {
+		cmd := setupDocsCommand()
+		_, _, err := executeCommand(cmd, "-d", moduleDir, "--check")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--check requires --readme")
+	})
+
+	t.Run("module without variables", func(t *testing.T) {
+		dir := writeModule(t, "output \"id\" {\n  value = \"x\"\n}\n")
+
+		cmd := setupDocsCommand()
+		stdout, _, err := executeCommand(cmd, "-d", dir)
+		require.NoError(t, err)
+		assert.NotContains(t, stdout, "## Inputs")
+		assert.Contains(t, stdout, "## Outputs")
+	})
+}
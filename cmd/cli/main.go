@@ -7,6 +7,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/samart/terraform-schema-generator/pkg/generator"
+	"github.com/samart/terraform-schema-generator/pkg/parser"
 )
 
 const version = "1.0.0"
@@ -18,6 +19,8 @@ var (
 	outputFile string
 	validate   bool
 	verbose    bool
+	strict     bool
+	metaSchema string
 )
 
 func main() {
@@ -50,10 +53,42 @@ func init() {
 
 	// Validation flags
 	rootCmd.Flags().BoolVar(&validate, "validate", true, "Validate generated schema against JSON Schema Draft 7")
+	rootCmd.Flags().StringVar(&metaSchema, "meta-schema", "", "Local meta-schema file to validate against instead of the embedded Draft 7 meta-schema")
 	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	rootCmd.Flags().BoolVar(&strict, "strict", true, "Fail on any parse error instead of skipping the affected files")
 
 	// Mark at least one input source as required
 	rootCmd.MarkFlagsMutuallyExclusive("dir", "file")
+
+	rootCmd.AddCommand(newValidateCommand())
+	rootCmd.AddCommand(newDiffCommand())
+	rootCmd.AddCommand(newDocsCommand())
+	rootCmd.AddCommand(newTFVarsCommand())
+	rootCmd.AddCommand(newScaffoldCommand())
+	rootCmd.AddCommand(newCheckCommand())
+}
+
+// generateOptions controls how a schema is generated. Each command fills
+// it from its own flags
+type generateOptions struct {
+	inputDir   string
+	inputFile  string
+	strict     bool
+	validate   bool
+	metaSchema string
+	verbose    bool
+}
+
+// rootOptions returns the generate options set by the root command's flags
+func rootOptions() generateOptions {
+	return generateOptions{
+		inputDir:   inputDir,
+		inputFile:  inputFile,
+		strict:     strict,
+		validate:   validate,
+		metaSchema: metaSchema,
+		verbose:    verbose,
+	}
 }
 
 func runGenerate(cmd *cobra.Command, args []string) error {
@@ -63,7 +98,7 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	}
 
 	// Generate schema
-	schemaJSON, err := generateSchema()
+	schemaJSON, err := generateSchema(rootOptions())
 	if err != nil {
 		return err // Error already has context from generateSchema
 	}
@@ -86,69 +121,91 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 }
 
 func validateInput() error {
-	if inputDir == "" && inputFile == "" {
+	return checkInput(rootOptions())
+}
+
+// checkInput checks that exactly one input is given and that it exists
+func checkInput(opts generateOptions) error {
+	if opts.inputDir == "" && opts.inputFile == "" {
 		return fmt.Errorf("either --dir or --file must be specified")
 	}
 
-	if inputDir != "" && inputFile != "" {
+	if opts.inputDir != "" && opts.inputFile != "" {
 		return fmt.Errorf("only one of --dir or --file can be specified")
 	}
 
 	// Validate input exists
-	if inputDir != "" {
-		info, err := os.Stat(inputDir)
+	if opts.inputDir != "" {
+		info, err := os.Stat(opts.inputDir)
 		if err != nil {
 			return fmt.Errorf("cannot access directory: %w", err)
 		}
 		if !info.IsDir() {
-			return fmt.Errorf("path is not a directory: %s", inputDir)
+			return fmt.Errorf("path is not a directory: %s", opts.inputDir)
 		}
-		if verbose {
-			fmt.Fprintf(os.Stderr, "→ Reading Terraform files from directory: %s\n", inputDir)
+		if opts.verbose {
+			fmt.Fprintf(os.Stderr, "→ Reading Terraform files from directory: %s\n", opts.inputDir)
 		}
 	}
 
-	if inputFile != "" {
-		info, err := os.Stat(inputFile)
+	if opts.inputFile != "" {
+		info, err := os.Stat(opts.inputFile)
 		if err != nil {
 			return fmt.Errorf("cannot access file: %w", err)
 		}
 		if info.IsDir() {
-			return fmt.Errorf("path is a directory, not a file: %s", inputFile)
+			return fmt.Errorf("path is a directory, not a file: %s", opts.inputFile)
 		}
-		if verbose {
-			fmt.Fprintf(os.Stderr, "→ Reading Terraform file: %s\n", inputFile)
+		if opts.verbose {
+			fmt.Fprintf(os.Stderr, "→ Reading Terraform file: %s\n", opts.inputFile)
 		}
 	}
 
 	return nil
 }
 
-func generateSchema() ([]byte, error) {
-	if verbose {
+// generateSchema parses, converts and optionally validates the input of
+// opts, returning the schema as JSON
+func generateSchema(opts generateOptions) ([]byte, error) {
+	if opts.verbose {
 		fmt.Fprintln(os.Stderr, "→ Parsing Terraform configuration...")
 	}
 
 	// Build generator
-	gen := generator.New()
+	genOpts := []generator.Option{generator.WithStrict(opts.strict)}
+	if opts.metaSchema != "" {
+		genOpts = append(genOpts, generator.WithMetaSchemaFile(opts.metaSchema))
+	}
+	gen := generator.New(genOpts...)
 
 	// Add input source
-	if inputDir != "" {
-		gen = gen.FromDirectory(inputDir)
+	if opts.inputDir != "" {
+		gen = gen.FromDirectory(opts.inputDir)
 	} else {
-		gen = gen.FromFile(inputFile)
+		gen = gen.FromFile(opts.inputFile)
 	}
 
 	// Parse
 	gen = gen.Parse()
 
-	// Check parsing errors
+	// Show diagnostics with the offending source. In lenient mode they are
+	// reported but generation carries on with whatever could be parsed
+	if diags := gen.Diagnostics(); len(diags) > 0 {
+		if err := parser.WriteDiagnostics(os.Stderr, diags); err != nil {
+			return nil, fmt.Errorf("failed to write diagnostics: %w", err)
+		}
+	}
+
+	// Check parsing errors. Diagnostics were already shown in full above
+	if diags := gen.Diagnostics(); opts.strict && diags.HasErrors() {
+		return nil, fmt.Errorf("parsing failed: %d error(s) in configuration", len(diags.Errors()))
+	}
 	if err := gen.Error(); err != nil {
 		return nil, fmt.Errorf("parsing failed: %w", err)
 	}
 
 	// Show parse results if verbose
-	if verbose {
+	if opts.verbose {
 		result, err := gen.ParseResult()
 		if err != nil {
 			return nil, fmt.Errorf("failed to get parse result: %w", err)
@@ -157,8 +214,14 @@ func generateSchema() ([]byte, error) {
 		fmt.Fprintln(os.Stderr, "→ Converting to JSON Schema Draft 7...")
 	}
 
-	// Convert to JSON Schema
+	// Convert to JSON Schema, reporting rules that could not be translated
+	parseDiags := len(gen.Diagnostics())
 	gen = gen.Convert()
+	if diags := gen.Diagnostics()[parseDiags:]; len(diags) > 0 {
+		if err := parser.WriteDiagnostics(os.Stderr, diags); err != nil {
+			return nil, fmt.Errorf("failed to write diagnostics: %w", err)
+		}
+	}
 
 	// Check conversion errors
 	if err := gen.Error(); err != nil {
@@ -166,9 +229,13 @@ func generateSchema() ([]byte, error) {
 	}
 
 	// Validate if requested
-	if validate {
-		if verbose {
-			fmt.Fprintln(os.Stderr, "→ Validating against JSON Schema Draft 7 meta-schema...")
+	if opts.validate {
+		if opts.verbose {
+			if opts.metaSchema != "" {
+				fmt.Fprintf(os.Stderr, "→ Validating against meta-schema %s...\n", opts.metaSchema)
+			} else {
+				fmt.Fprintln(os.Stderr, "→ Validating against JSON Schema Draft 7 meta-schema...")
+			}
 		}
 		gen = gen.ValidateAgainstMetaSchema()
 
@@ -177,7 +244,7 @@ func generateSchema() ([]byte, error) {
 			return nil, fmt.Errorf("schema validation failed: %w", err)
 		}
 
-		if verbose {
+		if opts.verbose {
 			fmt.Fprintln(os.Stderr, "✓ Schema validation passed")
 		}
 	}
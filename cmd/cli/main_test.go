@@ -20,6 +20,8 @@ func setupTestCommand() *cobra.Command {
 	outputFile = ""
 	validate = true
 	verbose = false
+	strict = true
+	metaSchema = ""
 
 	// Create a new root command instance
 	cmd := &cobra.Command{
@@ -35,7 +37,9 @@ func setupTestCommand() *cobra.Command {
 	cmd.Flags().StringVarP(&inputFile, "file", "f", "", "Single Terraform file to process")
 	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file path (default: stdout)")
 	cmd.Flags().BoolVar(&validate, "validate", true, "Validate generated schema against JSON Schema Draft 7")
+	cmd.Flags().StringVar(&metaSchema, "meta-schema", "", "Local meta-schema file to validate against instead of the embedded Draft 7 meta-schema")
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	cmd.Flags().BoolVar(&strict, "strict", true, "Fail on any parse error instead of skipping the affected files")
 	cmd.MarkFlagsMutuallyExclusive("dir", "file")
 
 	return cmd
@@ -552,3 +556,110 @@ func TestValidateInput(t *testing.T) {
 		})
 	}
 }
+
+func TestCLI_Diagnostics(t *testing.T) {
+	tmpDir := t.TempDir()
+	tfFile := filepath.Join(tmpDir, "variables.tf")
+
+	tfContent := "variable \"port\" {\n  type    = number\n  default = \"http\"\n}\n"
+	require.NoError(t, os.WriteFile(tfFile, []byte(tfContent), 0644))
+
+	cmd := setupTestCommand()
+	_, stderr, err := executeCommand(cmd, "-f", tfFile)
+
+	require.Error(t, err)
+	assert.Contains(t, stderr, "Error: Invalid default value for variable")
+	assert.Contains(t, stderr, "line 3:")
+	assert.Contains(t, stderr, "     3:   default = \"http\"\n")
+	assert.Contains(t, stderr, "                    ^^^^^^\n")
+	assert.Contains(t, stderr, "parsing failed: 1 error(s) in configuration")
+}
+
+func TestCLI_StrictMode(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "good.tf"), []byte(`
+variable "name" {
+  type = string
+}
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "broken.tf"), []byte(`
+variable "broken" {
+  type = string
+`), 0644))
+
+	t.Run("strict by default", func(t *testing.T) {
+		cmd := setupTestCommand()
+		stdout, stderr, err := executeCommand(cmd, "-d", tmpDir)
+
+		require.Error(t, err)
+		assert.Empty(t, stdout)
+		assert.Contains(t, stderr, "broken.tf")
+		assert.Contains(t, stderr, "parsing failed")
+	})
+
+	t.Run("lenient mode reports and continues", func(t *testing.T) {
+		cmd := setupTestCommand()
+		stdout, stderr, err := executeCommand(cmd, "-d", tmpDir, "--strict=false")
+
+		require.NoError(t, err)
+		assert.Contains(t, stderr, "broken.tf")
+		assert.Contains(t, stdout, `"name"`)
+		assert.NotContains(t, stdout, `"broken"`)
+	})
+}
+
+func TestCLI_ConversionWarnings(t *testing.T) {
+	tmpDir := t.TempDir()
+	tfFile := filepath.Join(tmpDir, "variables.tf")
+
+	tfContent := `
+variable "name" {
+  type = string
+  validation {
+    condition     = can(regex("(?m)^[a-z]+$", var.name))
+    error_message = "Lowercase only."
+  }
+}
+`
+	require.NoError(t, os.WriteFile(tfFile, []byte(tfContent), 0644))
+
+	cmd := setupTestCommand()
+	stdout, stderr, err := executeCommand(cmd, "-f", tfFile)
+
+	require.NoError(t, err)
+	assert.Contains(t, stdout, `"name"`)
+	assert.Contains(t, stderr, "Warning: Validation pattern not translated")
+}
+
+func TestCLI_MetaSchema(t *testing.T) {
+	tmpDir := t.TempDir()
+	tfFile := filepath.Join(tmpDir, "variables.tf")
+	require.NoError(t, os.WriteFile(tfFile, []byte(`
+variable "name" {
+  type = string
+}
+`), 0644))
+
+	t.Run("custom meta-schema accepts the schema", func(t *testing.T) {
+		metaFile := filepath.Join(tmpDir, "meta.json")
+		require.NoError(t, os.WriteFile(metaFile, []byte(`{"required": ["properties"]}`), 0644))
+
+		cmd := setupTestCommand()
+		stdout, stderr, err := executeCommand(cmd, "-f", tfFile, "--meta-schema", metaFile, "-v")
+
+		require.NoError(t, err)
+		assert.Contains(t, stdout, `"name"`)
+		assert.Contains(t, stderr, "Validating against meta-schema "+metaFile)
+	})
+
+	t.Run("custom meta-schema rejects the schema", func(t *testing.T) {
+		metaFile := filepath.Join(tmpDir, "strict-meta.json")
+		require.NoError(t, os.WriteFile(metaFile, []byte(`{"required": ["x-owner"]}`), 0644))
+
+		cmd := setupTestCommand()
+		_, _, err := executeCommand(cmd, "-f", tfFile, "--meta-schema", metaFile)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "x-owner")
+	})
+}
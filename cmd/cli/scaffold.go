@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/samart/terraform-schema-generator/pkg/scaffold"
+)
+
+// scaffoldOptions holds the flags of the scaffold command
+type scaffoldOptions struct {
+	dir     string
+	output  string
+	minimal bool
+	strict  bool
+}
+
+// newScaffoldCommand creates the scaffold subcommand, which writes an
+// example inputs file for a module
+func newScaffoldCommand() *cobra.Command {
+	opts := &scaffoldOptions{}
+	cmd := &cobra.Command{
+		Use:   "scaffold --dir MODULE",
+		Short: "Generate a commented example inputs file for a Terraform module",
+		Long: `Writes a YAML inputs file listing every variable of a module, with its
+description as a comment and its default as the value. Required variables
+get placeholders of their type, with objects expanded into their
+attributes; each placeholder is marked with a # ` + scaffold.Placeholder + ` comment.
+
+Apart from the placeholders, the file validates against the module's
+schema. Use --minimal to list only the required variables.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runScaffold(opts)
+		},
+		SilenceUsage: true,
+	}
+
+	cmd.Flags().StringVarP(&opts.dir, "dir", "d", "", "Directory containing the Terraform module")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Output file path (default: stdout)")
+	cmd.Flags().BoolVar(&opts.minimal, "minimal", false, "Only include required variables")
+	cmd.Flags().BoolVar(&opts.strict, "strict", true, "Fail on any parse error instead of skipping the affected files")
+	_ = cmd.MarkFlagRequired("dir")
+
+	return cmd
+}
+
+func runScaffold(opts *scaffoldOptions) error {
+	if err := checkInput(generateOptions{inputDir: opts.dir}); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	result, schema, err := parseModule(opts.dir, opts.strict)
+	if err != nil {
+		return err
+	}
+
+	var scaffoldOpts []scaffold.Option
+	if opts.minimal {
+		scaffoldOpts = append(scaffoldOpts, scaffold.WithMinimal())
+	}
+	out, err := scaffold.YAML(result, schema, scaffoldOpts...)
+	if err != nil {
+		return err
+	}
+
+	if opts.output == "" {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	if err := os.WriteFile(opts.output, out, 0644); err != nil {
+		return fmt.Errorf("failed to write output file %s: %w", opts.output, err)
+	}
+	return nil
+}
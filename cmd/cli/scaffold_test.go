@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupScaffoldCommand creates a fresh scaffold command, whose flags start at their defaults
+func setupScaffoldCommand() *cobra.Command {
+	return newScaffoldCommand()
+}
+
+func TestCLI_Scaffold(t *testing.T) {
+	moduleDir := writeModule(t, `
+variable "environment" {
+  type        = string
+  description = "Deployment environment"
+  validation {
+    condition     = contains(["dev", "prod"], var.environment)
+    error_message = "Unknown environment."
+  }
+}
+
+variable "replicas" {
+  type    = number
+  default = 1
+}
+`)
+
+	t.Run("prints to stdout", func(t *testing.T) {
+		cmd := setupScaffoldCommand()
+		stdout, _, err := executeCommand(cmd, "--dir", moduleDir)
+
+		require.NoError(t, err)
+		assert.Equal(t, "# Deployment environment\nenvironment: dev # TODO: required string\n\nreplicas: 1\n", stdout)
+	})
+
+	t.Run("minimal scaffold to a file validates", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "inputs.yaml")
+
+		cmd := setupScaffoldCommand()
+		_, _, err := executeCommand(cmd, "-d", moduleDir, "--minimal", "-o", out)
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(out)
+		require.NoError(t, err)
+		assert.Equal(t, "# Deployment environment\nenvironment: dev # TODO: required string\n", string(content))
+
+		validateCmd := setupValidateCommand()
+		_, _, err = executeCommand(validateCmd, "--dir", moduleDir, out)
+		assert.NoError(t, err)
+	})
+}
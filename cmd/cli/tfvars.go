@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/samart/terraform-schema-generator/pkg/tfvars"
+	"github.com/samart/terraform-schema-generator/pkg/validator"
+)
+
+// formatHCL selects native syntax .tfvars output, alongside formatJSON
+const formatHCL = "hcl"
+
+// tfvarsOptions holds the flags of the tfvars command
+type tfvarsOptions struct {
+	dir     string
+	output  string
+	format  string
+	secrets string
+	envFile string
+	strict  bool
+}
+
+// newTFVarsCommand creates the tfvars subcommand, which validates an input
+// file and converts it into variable definitions Terraform reads
+func newTFVarsCommand() *cobra.Command {
+	opts := &tfvarsOptions{}
+	cmd := &cobra.Command{
+		Use:   "tfvars --dir MODULE INPUT",
+		Short: "Convert validated inputs into a Terraform variable definitions file",
+		Long: `Validates an input file (YAML, JSON or .tfvars) against a module's schema
+and writes it as terraform.tfvars.json or native .tfvars, with every value
+converted to its variable's declared type. Keys that are not variables of
+the module are rejected.
+
+The format follows the --output extension (.json for JSON, HCL otherwise)
+unless --format is given, and defaults to JSON on stdout.
+
+Sensitive and ephemeral variables are left out by default, so that secrets
+do not end up in a file that may be committed. --secrets include writes
+them like any other variable, and env writes them to --env-file as TF_VAR_
+exports instead, leaving null ones unset.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTFVars(opts, args)
+		},
+		SilenceUsage: true,
+	}
+
+	cmd.Flags().StringVarP(&opts.dir, "dir", "d", "", "Directory containing the Terraform module")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Output file path (default: stdout)")
+	cmd.Flags().StringVar(&opts.format, "format", "", "Output format: json or hcl")
+	cmd.Flags().StringVar(&opts.secrets, "secrets", string(tfvars.SecretsOmit), "Handling of sensitive and ephemeral variables: include, omit or env")
+	cmd.Flags().StringVar(&opts.envFile, "env-file", "", "File to write TF_VAR_ exports to when --secrets is env")
+	cmd.Flags().BoolVar(&opts.strict, "strict", true, "Fail on any parse error instead of skipping the affected files")
+	_ = cmd.MarkFlagRequired("dir")
+
+	return cmd
+}
+
+func runTFVars(opts *tfvarsOptions, args []string) error {
+	format := opts.format
+	if format == "" {
+		format = formatJSON
+		if opts.output != "" && !strings.HasSuffix(opts.output, ".json") {
+			format = formatHCL
+		}
+	}
+	if format != formatJSON && format != formatHCL {
+		return fmt.Errorf("invalid format %q: must be %s or %s", format, formatJSON, formatHCL)
+	}
+	secrets, err := tfvars.ParseSecretMode(opts.secrets)
+	if err != nil {
+		return err
+	}
+	if secrets == tfvars.SecretsEnv && opts.envFile == "" {
+		return fmt.Errorf("--secrets env requires --env-file")
+	}
+	if err := checkInput(generateOptions{inputDir: opts.dir}); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	result, schema, err := parseModule(opts.dir, opts.strict)
+	if err != nil {
+		return err
+	}
+	values, err := validator.DecodeInputFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	// Only validated inputs are converted
+	if schema != nil {
+		v, err := validator.NewInputValidator(schema)
+		if err != nil {
+			return err
+		}
+		validation, err := v.ValidateValues(values)
+		if err != nil {
+			return err
+		}
+		if !validation.Valid {
+			for _, inputErr := range validation.Errors {
+				fmt.Fprintf(os.Stderr, "  %s\n", inputErr.Error())
+			}
+			return fmt.Errorf("%s failed validation", args[0])
+		}
+	}
+
+	converted, err := tfvars.NewConverter(result, tfvars.WithSecrets(secrets)).Convert(values)
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+
+	if len(converted.Omitted) > 0 {
+		fmt.Fprintf(os.Stderr, "→ Left out sensitive or ephemeral variable(s), see --secrets: %s\n", strings.Join(converted.Omitted, ", "))
+	}
+
+	var out []byte
+	if format == formatJSON {
+		if out, err = converted.JSON(); err != nil {
+			return err
+		}
+	} else {
+		out = converted.HCL()
+	}
+
+	if secrets == tfvars.SecretsEnv {
+		if err := os.WriteFile(opts.envFile, converted.EnvExports(), 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", opts.envFile, err)
+		}
+	}
+
+	if opts.output == "" {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	if err := os.WriteFile(opts.output, out, 0644); err != nil {
+		return fmt.Errorf("failed to write output file %s: %w", opts.output, err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTFVarsCommand creates a fresh tfvars command, whose flags start at their defaults
+func setupTFVarsCommand() *cobra.Command {
+	return newTFVarsCommand()
+}
+
+func TestCLI_TFVars(t *testing.T) {
+	moduleDir := writeModule(t, `
+variable "environment" {
+  type = string
+  validation {
+    condition     = contains(["dev", "prod"], var.environment)
+    error_message = "Unknown environment."
+  }
+}
+
+variable "replicas" {
+  type    = number
+  default = 1
+}
+
+variable "api_key" {
+  type      = string
+  sensitive = true
+  default   = null
+}
+`)
+	inputsDir := t.TempDir()
+	writeInput := func(name, content string) string {
+		path := filepath.Join(inputsDir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	valid := writeInput("inputs.yaml", "environment: prod\nreplicas: 2\napi_key: s3cr3t\n")
+
+	t.Run("JSON on stdout without secrets", func(t *testing.T) {
+		cmd := setupTFVarsCommand()
+		stdout, stderr, err := executeCommand(cmd, "--dir", moduleDir, valid)
+
+		require.NoError(t, err)
+		assert.Equal(t, "{\n  \"environment\": \"prod\",\n  \"replicas\": 2\n}\n", stdout)
+		assert.Contains(t, stderr, "Left out sensitive or ephemeral variable(s), see --secrets: api_key")
+	})
+
+	t.Run("secrets included on request", func(t *testing.T) {
+		cmd := setupTFVarsCommand()
+		stdout, _, err := executeCommand(cmd, "--dir", moduleDir, "--secrets", "include", valid)
+
+		require.NoError(t, err)
+		assert.Equal(t, "{\n  \"environment\": \"prod\",\n  \"replicas\": 2,\n  \"api_key\": \"s3cr3t\"\n}\n", stdout)
+	})
+
+	t.Run("HCL file with secrets in an env file", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "terraform.tfvars")
+		envFile := filepath.Join(t.TempDir(), "secrets.env")
+
+		cmd := setupTFVarsCommand()
+		_, _, err := executeCommand(cmd, "-d", moduleDir, "-o", out, "--secrets", "env", "--env-file", envFile, valid)
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(out)
+		require.NoError(t, err)
+		assert.Equal(t, "environment = \"prod\"\nreplicas    = 2\n", string(content))
+
+		exports, err := os.ReadFile(envFile)
+		require.NoError(t, err)
+		assert.Equal(t, "export TF_VAR_api_key='s3cr3t'\n", string(exports))
+	})
+
+	t.Run("invalid input is not converted", func(t *testing.T) {
+		invalid := writeInput("invalid.yaml", "environment: staging\n")
+
+		cmd := setupTFVarsCommand()
+		stdout, stderr, err := executeCommand(cmd, "-d", moduleDir, invalid)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed validation")
+		assert.Contains(t, stderr, "/environment: Unknown environment.")
+		assert.Empty(t, stdout)
+	})
+
+	t.Run("unknown keys are rejected", func(t *testing.T) {
+		unknown := writeInput("unknown.yaml", "environment: dev\nenviroment: prod\n")
+
+		cmd := setupTFVarsCommand()
+		_, _, err := executeCommand(cmd, "-d", moduleDir, unknown)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "undeclared variable(s) enviroment")
+	})
+
+	t.Run("env secrets need an env file", func(t *testing.T) {
+		cmd := setupTFVarsCommand()
+		_, _, err := executeCommand(cmd, "-d", moduleDir, "--secrets", "env", valid)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--env-file")
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		cmd := setupTFVarsCommand()
+		_, _, err := executeCommand(cmd, "-d", moduleDir, "--format", "yaml", valid)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid format "yaml"`)
+	})
+}
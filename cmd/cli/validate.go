@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/samart/terraform-schema-generator/pkg/validator"
+)
+
+// Output formats of the validate command
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+// validateOptions holds the flags of the validate command
+type validateOptions struct {
+	dir    string
+	format string
+	strict bool
+}
+
+// newValidateCommand creates the validate subcommand, which checks input
+// files against the schema of a module without writing the schema out
+func newValidateCommand() *cobra.Command {
+	opts := &validateOptions{}
+	cmd := &cobra.Command{
+		Use:   "validate --dir MODULE INPUT [INPUT...]",
+		Short: "Validate input files against a Terraform module",
+		Long: `Generates the JSON Schema of a Terraform module in memory and validates
+each input file against it. Inputs can be YAML (.yaml, .yml), JSON (.json,
+.tfvars.json) or HCL variable definitions (.tfvars).
+
+Exits with a non-zero status when any input is invalid.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runValidate(opts, args)
+		},
+		SilenceUsage: true,
+	}
+
+	cmd.Flags().StringVarP(&opts.dir, "dir", "d", "", "Directory containing the Terraform module")
+	cmd.Flags().StringVar(&opts.format, "format", formatText, "Output format: text or json")
+	cmd.Flags().BoolVar(&opts.strict, "strict", true, "Fail on any parse error instead of skipping the affected files")
+	_ = cmd.MarkFlagRequired("dir")
+
+	return cmd
+}
+
+// fileReport is the validation outcome of one input file
+type fileReport struct {
+	File   string                 `json:"file"`
+	Valid  bool                   `json:"valid"`
+	Errors []validator.InputError `json:"errors"`
+
+	// Error is set when the file could not be read or parsed
+	Error string `json:"error,omitempty"`
+}
+
+// validationReport is the outcome of a validate run
+type validationReport struct {
+	Valid bool         `json:"valid"`
+	Files []fileReport `json:"files"`
+}
+
+func runValidate(opts *validateOptions, args []string) error {
+	if opts.format != formatText && opts.format != formatJSON {
+		return fmt.Errorf("invalid format %q: must be %s or %s", opts.format, formatText, formatJSON)
+	}
+	genOpts := generateOptions{inputDir: opts.dir, strict: opts.strict, validate: true}
+	if err := checkInput(genOpts); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	schemaJSON, err := generateSchema(genOpts)
+	if err != nil {
+		return err
+	}
+	v, err := validator.NewInputValidatorFromJSON(schemaJSON)
+	if err != nil {
+		return err
+	}
+
+	report := validationReport{Valid: true}
+	for _, path := range args {
+		file := fileReport{File: path, Errors: []validator.InputError{}}
+		result, err := v.ValidateFile(path)
+		if err != nil {
+			file.Error = err.Error()
+		} else {
+			file.Valid = result.Valid
+			file.Errors = result.Errors
+		}
+		report.Valid = report.Valid && file.Valid
+		report.Files = append(report.Files, file)
+	}
+
+	if opts.format == formatJSON {
+		err = writeJSONReport(os.Stdout, report)
+	} else {
+		err = writeTextReport(os.Stdout, report)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	if !report.Valid {
+		failed := 0
+		for _, file := range report.Files {
+			if !file.Valid {
+				failed++
+			}
+		}
+		return fmt.Errorf("%d of %d input file(s) failed validation", failed, len(report.Files))
+	}
+	return nil
+}
+
+// writeTextReport prints each file with its errors, one per line
+func writeTextReport(w io.Writer, report validationReport) error {
+	for _, file := range report.Files {
+		var err error
+		switch {
+		case file.Error != "":
+			_, err = fmt.Fprintf(w, "✗ %s\n  %s\n", file.File, file.Error)
+		case !file.Valid:
+			_, err = fmt.Fprintf(w, "✗ %s\n", file.File)
+			for _, inputErr := range file.Errors {
+				if err == nil {
+					_, err = fmt.Fprintf(w, "  %s\n", inputErr.Error())
+				}
+			}
+		default:
+			_, err = fmt.Fprintf(w, "✓ %s\n", file.File)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeJSONReport prints a report as indented JSON
+func writeJSONReport(w io.Writer, report interface{}) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupValidateCommand creates a fresh validate command, whose flags start at their defaults
+func setupValidateCommand() *cobra.Command {
+	return newValidateCommand()
+}
+
+// writeValidateFixture writes a module and returns its directory
+func writeValidateFixture(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "variables.tf"), []byte(`
+variable "environment" {
+  type = string
+  validation {
+    condition     = contains(["dev", "prod"], var.environment)
+    error_message = "Unknown environment."
+  }
+}
+
+variable "replicas" {
+  type    = number
+  default = 1
+}
+`), 0644))
+	return dir
+}
+
+func TestCLI_Validate(t *testing.T) {
+	moduleDir := writeValidateFixture(t)
+	inputsDir := t.TempDir()
+
+	valid := filepath.Join(inputsDir, "valid.yaml")
+	require.NoError(t, os.WriteFile(valid, []byte("environment: dev\nreplicas: 2\n"), 0644))
+	tfvars := filepath.Join(inputsDir, "prod.tfvars")
+	require.NoError(t, os.WriteFile(tfvars, []byte("environment = \"prod\"\n"), 0644))
+	invalid := filepath.Join(inputsDir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("environment: staging\nreplicas: many\n"), 0644))
+
+	t.Run("valid inputs", func(t *testing.T) {
+		cmd := setupValidateCommand()
+		stdout, _, err := executeCommand(cmd, "--dir", moduleDir, valid, tfvars)
+
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "✓ "+valid)
+		assert.Contains(t, stdout, "✓ "+tfvars)
+	})
+
+	t.Run("invalid input fails with annotated errors", func(t *testing.T) {
+		cmd := setupValidateCommand()
+		stdout, _, err := executeCommand(cmd, "-d", moduleDir, valid, invalid)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "1 of 2 input file(s) failed validation")
+		assert.Contains(t, stdout, "✓ "+valid)
+		assert.Contains(t, stdout, "✗ "+invalid)
+		assert.Contains(t, stdout, "/environment: Unknown environment.")
+		assert.Contains(t, stdout, "/replicas: ")
+	})
+
+	t.Run("JSON report", func(t *testing.T) {
+		cmd := setupValidateCommand()
+		stdout, _, err := executeCommand(cmd, "-d", moduleDir, "--format", "json", valid, invalid)
+		require.Error(t, err)
+
+		var report validationReport
+		require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+		assert.False(t, report.Valid)
+		require.Len(t, report.Files, 2)
+		assert.True(t, report.Files[0].Valid)
+		assert.Empty(t, report.Files[0].Errors)
+
+		assert.False(t, report.Files[1].Valid)
+		require.Len(t, report.Files[1].Errors, 2)
+		assert.Equal(t, "/environment", report.Files[1].Errors[0].Path)
+		assert.Equal(t, "enum", report.Files[1].Errors[0].Keyword)
+		assert.Equal(t, "Unknown environment.", report.Files[1].Errors[0].ErrorMessage)
+		assert.Equal(t, "/replicas", report.Files[1].Errors[1].Path)
+		assert.Equal(t, "type", report.Files[1].Errors[1].Keyword)
+	})
+
+	t.Run("unreadable input is reported", func(t *testing.T) {
+		cmd := setupValidateCommand()
+		stdout, _, err := executeCommand(cmd, "-d", moduleDir, "--format", "json", filepath.Join(inputsDir, "missing.yaml"))
+		require.Error(t, err)
+
+		var report validationReport
+		require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+		require.Len(t, report.Files, 1)
+		assert.False(t, report.Files[0].Valid)
+		assert.Contains(t, report.Files[0].Error, "failed to read")
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		cmd := setupValidateCommand()
+		_, _, err := executeCommand(cmd, "-d", moduleDir, "--format", "xml", valid)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid format "xml"`)
+	})
+
+	t.Run("module directory is required", func(t *testing.T) {
+		cmd := setupValidateCommand()
+		_, _, err := executeCommand(cmd, valid)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "dir")
+	})
+
+	t.Run("at least one input is required", func(t *testing.T) {
+		cmd := setupValidateCommand()
+		_, _, err := executeCommand(cmd, "-d", moduleDir)
+		assert.Error(t, err)
+	})
+	t.Run("root command flags are not used", func(t *testing.T) {
+		setupTestCommand()
+		inputFile = valid
+		metaSchema = filepath.Join(inputsDir, "missing-meta.json")
+		verbose = true
+		defer setupTestCommand()
+
+		cmd := setupValidateCommand()
+		stdout, stderr, err := executeCommand(cmd, "-d", moduleDir, valid)
+
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "✓ "+valid)
+		assert.Empty(t, stderr)
+	})
+}
@@ -3,9 +3,12 @@ module github.com/samart/terraform-schema-generator
 go 1.21
 
 require (
+	github.com/apparentlymart/go-cidr v1.1.0
 	github.com/hashicorp/hcl/v2 v2.19.1
+	github.com/spf13/cobra v1.10.1
 	github.com/stretchr/testify v1.8.4
 	github.com/xeipuuv/gojsonschema v1.2.0
+	github.com/zclconf/go-cty v1.14.1
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -15,13 +18,10 @@ require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/google/go-cmp v0.6.0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/lainio/err2 v1.2.2 // indirect
 	github.com/mitchellh/go-wordwrap v1.0.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/spf13/cobra v1.10.1 // indirect
 	github.com/spf13/pflag v1.0.9 // indirect
 	github.com/xeipuuv/gojsonpointer v0.0.0-20180127040702-4e3ac2762d5f // indirect
 	github.com/xeipuuv/gojsonreference v0.0.0-20180127040603-bd5ef7bd5415 // indirect
-	github.com/zclconf/go-cty v1.14.1 // indirect
 	golang.org/x/text v0.14.0 // indirect
 )
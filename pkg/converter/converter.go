@@ -3,6 +3,7 @@ package converter
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/samart/terraform-schema-generator/pkg/parser"
@@ -21,27 +22,100 @@ type JSONSchema7 struct {
 
 // Property represents a JSON Schema property
 type Property struct {
-	Type        interface{} `json:"type,omitempty"`
-	Description string      `json:"description,omitempty"`
-	Default     interface{} `json:"default,omitempty"`
-	Format      string      `json:"format,omitempty"`
-	Pattern     string      `json:"pattern,omitempty"`
-	MinLength   *int        `json:"minLength,omitempty"`
-	MaxLength   *int        `json:"maxLength,omitempty"`
-	Minimum     *float64    `json:"minimum,omitempty"`
-	Maximum     *float64    `json:"maximum,omitempty"`
-	Items       *Property   `json:"items,omitempty"`
-	Enum        []string    `json:"enum,omitempty"`
-	Properties  interface{} `json:"properties,omitempty"`
-	WriteOnly   bool        `json:"writeOnly,omitempty"`
+	Type        interface{}   `json:"type,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+	Format      string        `json:"format,omitempty"`
+	Pattern     string        `json:"pattern,omitempty"`
+	MinLength   *int          `json:"minLength,omitempty"`
+	MaxLength   *int          `json:"maxLength,omitempty"`
+	Minimum     *float64      `json:"minimum,omitempty"`
+	Maximum     *float64      `json:"maximum,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+	WriteOnly   bool          `json:"writeOnly,omitempty"`
+
+	// ExclusiveMinimum and ExclusiveMaximum use the draft-07 numeric form
+	// and replace Minimum and Maximum when a bound is strict
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+
+	// Items is a *Property for list and set element types, or a []Property
+	// of positional schemas for tuple types
+	Items           interface{} `json:"items,omitempty"`
+	AdditionalItems interface{} `json:"additionalItems,omitempty"`
+	MinItems        *int        `json:"minItems,omitempty"`
+	MaxItems        *int        `json:"maxItems,omitempty"`
+	UniqueItems     bool        `json:"uniqueItems,omitempty"`
+
+	// Properties and Required describe object attributes, while
+	// AdditionalProperties holds the value schema of map types
+	Properties           map[string]Property `json:"properties,omitempty"`
+	Required             []string            `json:"required,omitempty"`
+	AdditionalProperties interface{}         `json:"additionalProperties,omitempty"`
+	MinProperties        *int                `json:"minProperties,omitempty"`
+	MaxProperties        *int                `json:"maxProperties,omitempty"`
+
+	// AllOf holds further constraints that cannot share the keywords above,
+	// such as a second pattern
+	AllOf []Property `json:"allOf,omitempty"`
+
+	// Order is the 1-based declaration position of the property, used by
+	// form renderers to display fields in module order
+	Order int `json:"x-order,omitempty"`
+
+	// Validations carries the variable's validation rules so that tools
+	// can show their error messages or evaluate the rules themselves
+	Validations []ValidationRule `json:"x-terraform-validations,omitempty"`
+
+	// Source points at the variable block the property was generated from
+	Source *parser.Range `json:"x-terraform-source,omitempty"`
+}
+
+// ValidationRule is a Terraform validation block as recorded in a schema.
+// Translated rules are fully enforced by the property's other keywords, and
+// Keywords lists those derived from the rule, translated or not. Keywords
+// of nested schemas are written as paths from the property, such as
+// allOf/1/pattern or items/enum
+type ValidationRule struct {
+	Condition    string   `json:"condition"`
+	ErrorMessage string   `json:"error_message"`
+	Translated   bool     `json:"translated"`
+	Keywords     []string `json:"keywords,omitempty"`
+}
+
+// addKeywords records keywords derived from the rule, without duplicates
+func (r *ValidationRule) addKeywords(keywords ...string) {
+	for _, keyword := range keywords {
+		if !slices.Contains(r.Keywords, keyword) {
+			r.Keywords = append(r.Keywords, keyword)
+		}
+	}
 }
 
 // Converter converts Terraform variables to JSON Schema 7
-type Converter struct{}
+type Converter struct {
+	includeSource bool
+	diagnostics   parser.Diagnostics
+}
+
+// Option configures a Converter
+type Option func(*Converter)
+
+// WithSourceLocations adds an x-terraform-source extension to every
+// top-level property, recording the file and lines of its variable block
+func WithSourceLocations() Option {
+	return func(c *Converter) {
+		c.includeSource = true
+	}
+}
 
 // NewConverter creates a new converter instance
-func NewConverter() *Converter {
-	return &Converter{}
+func NewConverter(opts ...Option) *Converter {
+	c := &Converter{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // ConvertToJSONSchema7 converts parsed Terraform variables to JSON Schema 7
@@ -50,6 +124,8 @@ func (c *Converter) ConvertToJSONSchema7(parseResult *parser.ParseResult) (*JSON
 		return nil, fmt.Errorf("no variables to convert")
 	}
 
+	c.diagnostics = nil
+
 	schema := &JSONSchema7{
 		Schema:      "http://json-schema.org/draft-07/schema#",
 		Title:       "Terraform Variables Schema",
@@ -60,8 +136,13 @@ func (c *Converter) ConvertToJSONSchema7(parseResult *parser.ParseResult) (*JSON
 		Definitions: make(map[string]interface{}),
 	}
 
-	for _, variable := range parseResult.Variables {
+	for i, variable := range parseResult.Variables {
 		property := c.convertVariable(variable)
+		property.Order = i + 1
+		if c.includeSource {
+			source := variable.Range
+			property.Source = &source
+		}
 		schema.Properties[variable.Name] = property
 
 		if variable.Required {
@@ -80,12 +161,103 @@ func (c *Converter) convertVariable(variable parser.Variable) Property {
 		WriteOnly:   variable.Sensitive,
 	}
 
-	// Map Terraform types to JSON Schema types
-	property.Type = c.mapTerraformTypeToJSONSchema(variable.Type)
+	// Map Terraform types to JSON Schema types, descending into nested
+	// types whenever the type constraint can be decoded
+	if spec := variableTypeSpec(variable); spec != nil {
+		c.applyTypeSpec(&property, spec)
+	} else {
+		property.Type = c.mapTerraformTypeToJSONSchema(variable.Type)
+	}
 
 	// Handle validation rules
-	c.applyValidationRules(&property, variable.Validations)
+	c.applyValidationRules(&property, variable)
+
+	// Nullable variables accept an explicit null from the caller
+	if variable.Nullable {
+		allowNull(&property)
+	}
+
+	return property
+}
+
+// allowNull adds "null" to the types a property accepts
+func allowNull(property *Property) {
+	switch t := property.Type.(type) {
+	case string:
+		property.Type = []string{t, "null"}
+	case []string:
+		for _, existing := range t {
+			if existing == "null" {
+				return
+			}
+		}
+		property.Type = append(t, "null")
+	}
+}
+
+// variableTypeSpec returns the decoded type constraint of a variable, or
+// nil when it has none or it cannot be decoded
+func variableTypeSpec(variable parser.Variable) *parser.TypeSpec {
+	if variable.TypeSpec != nil || variable.Type == "" {
+		return variable.TypeSpec
+	}
+	spec, _ := parser.ParseTypeString(variable.Type)
+	return spec
+}
+
+// applyTypeSpec sets the type keywords of a property from a decoded Terraform
+// type constraint, recursing into element, tuple and object attribute types
+func (c *Converter) applyTypeSpec(property *Property, spec *parser.TypeSpec) {
+	switch spec.Kind {
+	case parser.TypeList, parser.TypeSet:
+		property.Type = "array"
+		property.Items = c.typeSpecToProperty(spec.Element)
+		property.UniqueItems = spec.Kind == parser.TypeSet
+
+	case parser.TypeTuple:
+		items := make([]Property, len(spec.Elements))
+		for i, elem := range spec.Elements {
+			items[i] = *c.typeSpecToProperty(elem)
+		}
+		count := len(items)
+		property.Type = "array"
+		property.Items = items
+		property.AdditionalItems = false
+		property.MinItems = &count
+		property.MaxItems = &count
+
+	case parser.TypeMap:
+		property.Type = "object"
+		property.AdditionalProperties = c.typeSpecToProperty(spec.Element)
+
+	case parser.TypeObject:
+		property.Type = "object"
+		property.Properties = make(map[string]Property, len(spec.Attributes))
+		for i, attr := range spec.Attributes {
+			attrProperty := c.typeSpecToProperty(attr.Type)
+			attrProperty.Default = attr.Default
+			attrProperty.Order = i + 1
+
+			// Attributes declared without optional() must always be set,
+			// though, as in Terraform's type conversion, they may be set to
+			// null. Optional ones may be left out, which conformed defaults
+			// also record as null when they declare no default of their own
+			allowNull(attrProperty)
+			if !attr.Optional {
+				property.Required = append(property.Required, attr.Name)
+			}
+			property.Properties[attr.Name] = *attrProperty
+		}
 
+	default:
+		property.Type = c.mapTerraformTypeToJSONSchema(string(spec.Kind))
+	}
+}
+
+// typeSpecToProperty builds the schema for a nested type constraint
+func (c *Converter) typeSpecToProperty(spec *parser.TypeSpec) *Property {
+	property := &Property{}
+	c.applyTypeSpec(property, spec)
 	return property
 }
 
@@ -128,37 +300,100 @@ func (c *Converter) mapTerraformTypeToJSONSchema(tfType string) interface{} {
 	return "string"
 }
 
-// applyValidationRules applies Terraform validation rules to JSON Schema properties
-func (c *Converter) applyValidationRules(property *Property, rules []parser.Validation) {
-	for _, rule := range rules {
-		// Parse common validation patterns
-		condition := strings.ToLower(rule.Condition)
+// Diagnostics returns the warnings reported by the last conversion, such as
+// validation rules that could not be expressed in JSON Schema
+func (c *Converter) Diagnostics() parser.Diagnostics {
+	return c.diagnostics
+}
 
-		// Length validations for strings
-		if strings.Contains(condition, "length") {
-			if strings.Contains(condition, ">=") || strings.Contains(condition, "min") {
-				minLen := 1
-				property.MinLength = &minLen
-			}
-			if strings.Contains(condition, "<=") || strings.Contains(condition, "max") {
-				maxLen := 255
-				property.MaxLength = &maxLen
+// warn records a warning about the variable element at rng
+func (c *Converter) warn(summary, detail string, rng parser.Range) {
+	diag := parser.Diagnostic{
+		Severity: parser.SeverityWarning,
+		Summary:  summary,
+		Detail:   detail,
+	}
+	if rng.Filename != "" {
+		diag.Subject = &rng
+	}
+	c.diagnostics = append(c.diagnostics, diag)
+}
+
+// ParseJSONSchema7 decodes a schema written by ToJSON. Nested items and
+// additionalProperties schemas are decoded back into Property values, so
+// the result has the same shape as a freshly converted schema
+func ParseJSONSchema7(data []byte) (*JSONSchema7, error) {
+	var schema JSONSchema7
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	for name, property := range schema.Properties {
+		if err := normalizeProperty(&property); err != nil {
+			return nil, fmt.Errorf("failed to parse schema of %q: %w", name, err)
+		}
+		schema.Properties[name] = property
+	}
+	return &schema, nil
+}
+
+// normalizeProperty replaces the generic JSON values that unmarshaling
+// leaves in Items and AdditionalProperties with Property values
+func normalizeProperty(property *Property) error {
+	switch items := property.Items.(type) {
+	case map[string]interface{}:
+		item, err := decodeProperty(items)
+		if err != nil {
+			return err
+		}
+		property.Items = item
+	case []interface{}:
+		tuple := make([]Property, len(items))
+		for i, raw := range items {
+			item, err := decodeProperty(raw)
+			if err != nil {
+				return err
 			}
+			tuple[i] = *item
 		}
+		property.Items = tuple
+	}
 
-		// Pattern validations
-		if strings.Contains(condition, "regex") || strings.Contains(condition, "can(regex") {
-			// Extract regex pattern if possible
-			// This is simplified - production code would parse the actual regex
-			property.Pattern = ".*"
+	if values, ok := property.AdditionalProperties.(map[string]interface{}); ok {
+		value, err := decodeProperty(values)
+		if err != nil {
+			return err
 		}
+		property.AdditionalProperties = value
+	}
 
-		// Enum validations
-		if strings.Contains(condition, "contains") { //nolint:staticcheck // Placeholder for future enum validation
-			// This would require parsing the actual values
-			// Simplified for now
+	for name, attr := range property.Properties {
+		if err := normalizeProperty(&attr); err != nil {
+			return err
 		}
+		property.Properties[name] = attr
+	}
+	for i := range property.AllOf {
+		if err := normalizeProperty(&property.AllOf[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeProperty decodes a generic JSON value into a Property
+func decodeProperty(raw interface{}) (*Property, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var property Property
+	if err := json.Unmarshal(data, &property); err != nil {
+		return nil, err
+	}
+	if err := normalizeProperty(&property); err != nil {
+		return nil, err
 	}
+	return &property, nil
 }
 
 // ToJSON converts the schema to JSON bytes
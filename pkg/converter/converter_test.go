@@ -232,9 +232,27 @@ func TestConvertToJSONSchema7(t *testing.T) {
 		require.NoError(t, err)
 		prop := schema.Properties["optional_string"]
 		// Nullable should allow null type
-		if typeSlice, ok := prop.Type.([]interface{}); ok {
-			assert.Contains(t, typeSlice, "null")
+		assert.Equal(t, []string{"string", "null"}, prop.Type)
+	})
+
+	t.Run("convert nullable complex and any types", func(t *testing.T) {
+		parseResult := &parser.ParseResult{
+			Variables: []parser.Variable{
+				{Name: "names", Type: "list(string)", Nullable: true},
+				{Name: "anything", Type: "any", Nullable: true},
+			},
 		}
+
+		schema, err := converter.ConvertToJSONSchema7(parseResult)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"array", "null"}, schema.Properties["names"].Type)
+		// The element type itself is not nullable
+		items, ok := schema.Properties["names"].Items.(*Property)
+		require.True(t, ok)
+		assert.Equal(t, "string", items.Type)
+		// any already accepts null
+		assert.Equal(t, []string{"string", "number", "boolean", "object", "array", "null"}, schema.Properties["anything"].Type)
+		validateSchemaAgainstMetaSchema(t, schema)
 	})
 
 	t.Run("convert nullable variable false", func(t *testing.T) {
@@ -1038,3 +1056,277 @@ func TestOptionalTypeModifier(t *testing.T) {
 		validateSchemaAgainstMetaSchema(t, schema)
 	})
 }
+
+// TestNestedTypeConversion tests that nested Terraform types produce full nested schemas
+func TestNestedTypeConversion(t *testing.T) {
+	converter := NewConverter()
+
+	convert := func(t *testing.T, tfType string) Property {
+		t.Helper()
+		parseResult := &parser.ParseResult{
+			Variables: []parser.Variable{
+				{
+					Name: "value",
+					Type: tfType,
+				},
+			},
+		}
+
+		schema, err := converter.ConvertToJSONSchema7(parseResult)
+		require.NoError(t, err)
+		validateSchemaAgainstMetaSchema(t, schema)
+		return schema.Properties["value"]
+	}
+
+	t.Run("object attributes become properties", func(t *testing.T) {
+		prop := convert(t, "object({name = string, port = optional(number, 80), tags = optional(map(string))})")
+
+		assert.Equal(t, "object", prop.Type)
+		require.Len(t, prop.Properties, 3)
+		// Attributes accept null, as in Terraform, but required ones must
+		// still be present
+		assert.Equal(t, []string{"string", "null"}, prop.Properties["name"].Type)
+		assert.Equal(t, []string{"number", "null"}, prop.Properties["port"].Type)
+		assert.NotNil(t, prop.Properties["port"].Default)
+		assert.Equal(t, []string{"name"}, prop.Required)
+	})
+
+	t.Run("all optional attributes leave required empty", func(t *testing.T) {
+		prop := convert(t, "object({opt1 = optional(string), opt2 = optional(number)})")
+
+		assert.Len(t, prop.Properties, 2)
+		assert.Empty(t, prop.Required)
+	})
+
+	t.Run("list element schema", func(t *testing.T) {
+		prop := convert(t, "list(string)")
+
+		assert.Equal(t, "array", prop.Type)
+		items, ok := prop.Items.(*Property)
+		require.True(t, ok, "list items should be a single schema")
+		assert.Equal(t, "string", items.Type)
+		assert.False(t, prop.UniqueItems)
+	})
+
+	t.Run("set becomes unique items", func(t *testing.T) {
+		prop := convert(t, "set(number)")
+
+		assert.Equal(t, "array", prop.Type)
+		assert.True(t, prop.UniqueItems)
+		items, ok := prop.Items.(*Property)
+		require.True(t, ok)
+		assert.Equal(t, "number", items.Type)
+	})
+
+	t.Run("map becomes additionalProperties", func(t *testing.T) {
+		prop := convert(t, "map(bool)")
+
+		assert.Equal(t, "object", prop.Type)
+		assert.Empty(t, prop.Properties)
+		additional, ok := prop.AdditionalProperties.(*Property)
+		require.True(t, ok, "map values should be described by additionalProperties")
+		assert.Equal(t, "boolean", additional.Type)
+	})
+
+	t.Run("tuple becomes positional items", func(t *testing.T) {
+		prop := convert(t, "tuple([string, number, bool])")
+
+		assert.Equal(t, "array", prop.Type)
+		items, ok := prop.Items.([]Property)
+		require.True(t, ok, "tuple items should be positional")
+		require.Len(t, items, 3)
+		assert.Equal(t, "string", items[0].Type)
+		assert.Equal(t, "number", items[1].Type)
+		assert.Equal(t, "boolean", items[2].Type)
+		assert.Equal(t, false, prop.AdditionalItems)
+		require.NotNil(t, prop.MinItems)
+		require.NotNil(t, prop.MaxItems)
+		assert.Equal(t, 3, *prop.MinItems)
+		assert.Equal(t, 3, *prop.MaxItems)
+	})
+
+	t.Run("list of objects recurses all the way down", func(t *testing.T) {
+		prop := convert(t, "list(object({name = string, rules = optional(list(object({port = number, cidrs = set(string)})))}))")
+
+		items, ok := prop.Items.(*Property)
+		require.True(t, ok)
+		assert.Equal(t, "object", items.Type)
+		assert.Equal(t, []string{"name"}, items.Required)
+
+		rules := items.Properties["rules"]
+		assert.Equal(t, []string{"array", "null"}, rules.Type)
+		ruleItems, ok := rules.Items.(*Property)
+		require.True(t, ok)
+		assert.Equal(t, []string{"port", "cidrs"}, ruleItems.Required)
+
+		cidrs := ruleItems.Properties["cidrs"]
+		assert.True(t, cidrs.UniqueItems)
+	})
+
+	t.Run("map of objects", func(t *testing.T) {
+		prop := convert(t, "map(object({enabled = bool, value = string}))")
+
+		additional, ok := prop.AdditionalProperties.(*Property)
+		require.True(t, ok)
+		assert.Equal(t, "object", additional.Type)
+		assert.ElementsMatch(t, []string{"enabled", "value"}, additional.Required)
+	})
+
+	t.Run("nested any type", func(t *testing.T) {
+		prop := convert(t, "map(any)")
+
+		additional, ok := prop.AdditionalProperties.(*Property)
+		require.True(t, ok)
+		assert.NotNil(t, additional.Type)
+	})
+
+	t.Run("decoded type spec takes precedence", func(t *testing.T) {
+		spec, err := parser.ParseTypeString("list(number)")
+		require.NoError(t, err)
+
+		parseResult := &parser.ParseResult{
+			Variables: []parser.Variable{
+				{
+					Name:     "value",
+					Type:     "list(number)",
+					TypeSpec: spec,
+				},
+			},
+		}
+
+		schema, err := converter.ConvertToJSONSchema7(parseResult)
+		require.NoError(t, err)
+		items, ok := schema.Properties["value"].Items.(*Property)
+		require.True(t, ok)
+		assert.Equal(t, "number", items.Type)
+	})
+}
+
+func TestPropertyOrder(t *testing.T) {
+	converter := NewConverter()
+
+	spec, err := parser.ParseTypeString("object({ zeta = string, alpha = optional(number) })")
+	require.NoError(t, err)
+
+	parseResult := &parser.ParseResult{
+		Variables: []parser.Variable{
+			{Name: "vpc_id", Type: "string", Required: true},
+			{Name: "settings", Type: spec.String(), TypeSpec: spec},
+			{Name: "az_count", Type: "number", Required: true},
+		},
+	}
+
+	schema, err := converter.ConvertToJSONSchema7(parseResult)
+	require.NoError(t, err)
+
+	t.Run("top-level properties carry declaration order", func(t *testing.T) {
+		assert.Equal(t, 1, schema.Properties["vpc_id"].Order)
+		assert.Equal(t, 2, schema.Properties["settings"].Order)
+		assert.Equal(t, 3, schema.Properties["az_count"].Order)
+		assert.Equal(t, []string{"vpc_id", "az_count"}, schema.Required)
+	})
+
+	t.Run("object attributes carry declaration order", func(t *testing.T) {
+		attrs := schema.Properties["settings"].Properties
+		assert.Equal(t, 1, attrs["zeta"].Order)
+		assert.Equal(t, 2, attrs["alpha"].Order)
+	})
+
+	t.Run("order is emitted as x-order", func(t *testing.T) {
+		first, err := converter.ToJSON(schema)
+		require.NoError(t, err)
+		assert.Contains(t, string(first), `"x-order": 1`)
+
+		second, err := converter.ToJSON(schema)
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+	})
+}
+
+func TestSourceLocations(t *testing.T) {
+	parseResult := &parser.ParseResult{
+		Variables: []parser.Variable{
+			{
+				Name: "region",
+				Type: "string",
+				Range: parser.Range{
+					Filename: "variables.tf",
+					Start:    parser.Pos{Line: 4, Column: 1, Byte: 40},
+					End:      parser.Pos{Line: 7, Column: 2, Byte: 95},
+				},
+			},
+		},
+	}
+
+	t.Run("omitted by default", func(t *testing.T) {
+		schema, err := NewConverter().ConvertToJSONSchema7(parseResult)
+		require.NoError(t, err)
+		assert.Nil(t, schema.Properties["region"].Source)
+
+		out, err := NewConverter().ToJSON(schema)
+		require.NoError(t, err)
+		assert.NotContains(t, string(out), "x-terraform-source")
+	})
+
+	t.Run("emitted when enabled", func(t *testing.T) {
+		converter := NewConverter(WithSourceLocations())
+		schema, err := converter.ConvertToJSONSchema7(parseResult)
+		require.NoError(t, err)
+
+		source := schema.Properties["region"].Source
+		require.NotNil(t, source)
+		assert.Equal(t, "variables.tf", source.Filename)
+		assert.Equal(t, 4, source.Start.Line)
+		assert.Equal(t, 7, source.End.Line)
+
+		out, err := converter.ToJSON(schema)
+		require.NoError(t, err)
+
+		var doc map[string]interface{}
+		require.NoError(t, json.Unmarshal(out, &doc))
+		region := doc["properties"].(map[string]interface{})["region"].(map[string]interface{})
+		assert.Contains(t, region, "x-terraform-source")
+		validateSchemaAgainstMetaSchema(t, schema)
+	})
+}
+
+func TestParseJSONSchema7(t *testing.T) {
+	spec, err := parser.ParseTypeString(`object({
+  ports = list(object({ number = number }))
+  tags  = map(string)
+  pair  = tuple([string, number])
+})`)
+	require.NoError(t, err)
+
+	converter := NewConverter()
+	schema, err := converter.ConvertToJSONSchema7(&parser.ParseResult{
+		Variables: []parser.Variable{{Name: "service", TypeSpec: spec, Required: true}},
+	})
+	require.NoError(t, err)
+	out, err := converter.ToJSON(schema)
+	require.NoError(t, err)
+
+	parsed, err := ParseJSONSchema7(out)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"service"}, parsed.Required)
+
+	service := parsed.Properties["service"]
+	ports := service.Properties["ports"]
+	items, ok := ports.Items.(*Property)
+	require.True(t, ok, "list items should decode to a *Property")
+	assert.Equal(t, []string{"number"}, items.Required)
+
+	tags := service.Properties["tags"]
+	values, ok := tags.AdditionalProperties.(*Property)
+	require.True(t, ok, "map values should decode to a *Property")
+	assert.Equal(t, "string", values.Type)
+
+	pair := service.Properties["pair"]
+	tuple, ok := pair.Items.([]Property)
+	require.True(t, ok, "tuple items should decode to []Property")
+	assert.Len(t, tuple, 2)
+	assert.Equal(t, false, pair.AdditionalItems)
+
+	_, err = ParseJSONSchema7([]byte("{"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,326 @@
+package converter
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxECMARune is the largest code point an ECMA-262 pattern can match
+// without the u flag, which JSON Schema validators do not enable
+const maxECMARune = 0xFFFF
+
+// translateRegex converts a Go RE2 regular expression, as used by
+// Terraform's regex functions, into an equivalent ECMA-262 pattern.
+// Constructs that ECMA-262 cannot express with the same meaning are
+// reported as an error rather than approximated
+func translateRegex(pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", fmt.Errorf("invalid regular expression: %w", err)
+	}
+
+	var b strings.Builder
+	if err := writeECMA(&b, re); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// writeECMA prints a parsed regular expression in ECMA-262 syntax
+func writeECMA(b *strings.Builder, re *syntax.Regexp) error {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		b.WriteString("(?!)")
+
+	case syntax.OpEmptyMatch:
+		// Nothing to write
+
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 && hasOtherCase(r) {
+				// ECMA-262 has no inline flags, so spell out each case
+				if err := writeCharClass(b, foldRanges(r), false); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := writeLiteralRune(b, r); err != nil {
+				return err
+			}
+		}
+
+	case syntax.OpCharClass:
+		return writeCharClass(b, re.Rune, true)
+
+	case syntax.OpAnyCharNotNL:
+		// ECMA-262 "." also excludes \r, U+2028 and U+2029
+		b.WriteString(`[^\n]`)
+
+	case syntax.OpAnyChar:
+		b.WriteString(`[\s\S]`)
+
+	case syntax.OpBeginText:
+		b.WriteString("^")
+
+	case syntax.OpEndText:
+		// Without (?m), both dialects only match "$" at the very end
+		b.WriteString("$")
+
+	case syntax.OpBeginLine, syntax.OpEndLine:
+		return fmt.Errorf("multi-line anchors (?m) are not supported in JSON Schema patterns")
+
+	case syntax.OpWordBoundary:
+		b.WriteString(`\b`)
+
+	case syntax.OpNoWordBoundary:
+		b.WriteString(`\B`)
+
+	case syntax.OpCapture:
+		// Group names do not affect matching, so plain groups are enough
+		b.WriteString("(")
+		if err := writeECMA(b, re.Sub[0]); err != nil {
+			return err
+		}
+		b.WriteString(")")
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		if err := writeRepeatOperand(b, re.Sub[0]); err != nil {
+			return err
+		}
+		switch re.Op {
+		case syntax.OpStar:
+			b.WriteString("*")
+		case syntax.OpPlus:
+			b.WriteString("+")
+		case syntax.OpQuest:
+			b.WriteString("?")
+		default:
+			switch {
+			case re.Max == -1:
+				fmt.Fprintf(b, "{%d,}", re.Min)
+			case re.Min == re.Max:
+				fmt.Fprintf(b, "{%d}", re.Min)
+			default:
+				fmt.Fprintf(b, "{%d,%d}", re.Min, re.Max)
+			}
+		}
+		if re.Flags&syntax.NonGreedy != 0 {
+			b.WriteString("?")
+		}
+
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if sub.Op == syntax.OpAlternate {
+				if err := writeGroup(b, sub); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := writeECMA(b, sub); err != nil {
+				return err
+			}
+		}
+
+	case syntax.OpAlternate:
+		for i, sub := range re.Sub {
+			if i > 0 {
+				b.WriteString("|")
+			}
+			if err := writeECMA(b, sub); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("unsupported regular expression construct %q", re.String())
+	}
+
+	return nil
+}
+
+// writeRepeatOperand writes the operand of a repetition, grouping it when
+// it is longer than a single atom
+func writeRepeatOperand(b *strings.Builder, re *syntax.Regexp) error {
+	switch {
+	case re.Op == syntax.OpLiteral && len(re.Rune) == 1,
+		re.Op == syntax.OpCharClass,
+		re.Op == syntax.OpAnyChar,
+		re.Op == syntax.OpAnyCharNotNL,
+		re.Op == syntax.OpCapture:
+		return writeECMA(b, re)
+	}
+	return writeGroup(b, re)
+}
+
+// writeGroup writes re inside a non-capturing group
+func writeGroup(b *strings.Builder, re *syntax.Regexp) error {
+	b.WriteString("(?:")
+	if err := writeECMA(b, re); err != nil {
+		return err
+	}
+	b.WriteString(")")
+	return nil
+}
+
+// writeLiteralRune writes a single rune, escaping ECMA-262 metacharacters
+func writeLiteralRune(b *strings.Builder, r rune) error {
+	if r > maxECMARune {
+		return fmt.Errorf("code point U+%X is outside the range JSON Schema patterns can match", r)
+	}
+	if strings.ContainsRune(`\^$.|?*+()[]{}`, r) {
+		b.WriteRune('\\')
+		b.WriteRune(r)
+		return nil
+	}
+	writeEscapedRune(b, r)
+	return nil
+}
+
+// writeEscapedRune writes r, escaping control and non-printable characters
+func writeEscapedRune(b *strings.Builder, r rune) {
+	switch r {
+	case '\t':
+		b.WriteString(`\t`)
+	case '\n':
+		b.WriteString(`\n`)
+	case '\r':
+		b.WriteString(`\r`)
+	case '\f':
+		b.WriteString(`\f`)
+	case '\v':
+		b.WriteString(`\v`)
+	default:
+		if unicode.IsPrint(r) {
+			b.WriteRune(r)
+		} else {
+			fmt.Fprintf(b, `\u%04X`, r)
+		}
+	}
+}
+
+// writeCharClass writes a character class given as pairs of inclusive
+// rune ranges. Classes reaching the top of the Unicode range are written
+// as negated classes of their complement, which is how RE2 stores them
+func writeCharClass(b *strings.Builder, ranges []rune, allowShorthand bool) error {
+	if allowShorthand {
+		if shorthand, ok := classShorthand(ranges); ok {
+			b.WriteString(shorthand)
+			return nil
+		}
+	}
+
+	negated := false
+	if len(ranges) > 0 && ranges[len(ranges)-1] == unicode.MaxRune {
+		ranges = complementRanges(ranges)
+		negated = true
+		if len(ranges) == 0 {
+			b.WriteString(`[\s\S]`)
+			return nil
+		}
+	}
+
+	for _, r := range ranges {
+		if r > maxECMARune {
+			return fmt.Errorf("character class includes code points beyond U+FFFF, which JSON Schema patterns cannot match")
+		}
+	}
+
+	b.WriteString("[")
+	if negated {
+		b.WriteString("^")
+	}
+	for i := 0; i < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		writeClassRune(b, lo)
+		if hi > lo {
+			if hi > lo+1 {
+				b.WriteString("-")
+			}
+			writeClassRune(b, hi)
+		}
+	}
+	b.WriteString("]")
+	return nil
+}
+
+// writeClassRune writes a rune inside a character class
+func writeClassRune(b *strings.Builder, r rune) {
+	if strings.ContainsRune(`\]^-[`, r) {
+		b.WriteRune('\\')
+		b.WriteRune(r)
+		return
+	}
+	writeEscapedRune(b, r)
+}
+
+// classShorthand returns the ECMA-262 escape for classes that match
+// exactly the same characters in both dialects
+func classShorthand(ranges []rune) (string, bool) {
+	key := runesKey(ranges)
+	for _, c := range []struct {
+		ranges    []rune
+		shorthand string
+	}{
+		{[]rune{'0', '9'}, `\d`},
+		{[]rune{'0', '9', 'A', 'Z', '_', '_', 'a', 'z'}, `\w`},
+		{[]rune{0, '0' - 1, '9' + 1, unicode.MaxRune}, `\D`},
+		{[]rune{0, '0' - 1, '9' + 1, 'A' - 1, 'Z' + 1, '_' - 1, '_' + 1, 'a' - 1, 'z' + 1, unicode.MaxRune}, `\W`},
+	} {
+		if key == runesKey(c.ranges) {
+			return c.shorthand, true
+		}
+	}
+	return "", false
+}
+
+func runesKey(runes []rune) string {
+	parts := make([]string, len(runes))
+	for i, r := range runes {
+		parts[i] = strconv.Itoa(int(r))
+	}
+	return strings.Join(parts, ",")
+}
+
+// complementRanges returns the ranges of code points not in ranges
+func complementRanges(ranges []rune) []rune {
+	var out []rune
+	next := rune(0)
+	for i := 0; i < len(ranges); i += 2 {
+		if ranges[i] > next {
+			out = append(out, next, ranges[i]-1)
+		}
+		next = ranges[i+1] + 1
+	}
+	if next <= unicode.MaxRune {
+		out = append(out, next, unicode.MaxRune)
+	}
+	return out
+}
+
+// hasOtherCase reports whether r has case variants
+func hasOtherCase(r rune) bool {
+	return unicode.SimpleFold(r) != r
+}
+
+// foldRanges returns the single-rune ranges for every case variant of r,
+// in ascending order
+func foldRanges(r rune) []rune {
+	variants := []rune{r}
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		variants = append(variants, f)
+	}
+	for i := 1; i < len(variants); i++ {
+		for j := i; j > 0 && variants[j] < variants[j-1]; j-- {
+			variants[j], variants[j-1] = variants[j-1], variants[j]
+		}
+	}
+
+	ranges := make([]rune, 0, len(variants)*2)
+	for _, v := range variants {
+		ranges = append(ranges, v, v)
+	}
+	return ranges
+}
@@ -0,0 +1,81 @@
+package converter
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslateRegex(t *testing.T) {
+	t.Run("patterns with identical syntax", func(t *testing.T) {
+		cases := map[string]string{
+			`^[a-z0-9-]+$`:                 `^[\-0-9a-z]+$`,
+			`^\d{3}-\d{4}$`:                `^\d{3}-\d{4}$`,
+			`^\w+$`:                        `^\w+$`,
+			`^(foo|bar)$`:                  `^(foo|bar)$`,
+			`^[A-Z]{2,}$`:                  `^[A-Z]{2,}$`,
+			`^a{1,3}?$`:                    `^a{1,3}?$`,
+			`\bword\b`:                     `\bword\b`,
+			`^arn:aws:iam::\d{12}:role/.+`: `^arn:aws:iam::\d{12}:role/[^\n]+`,
+			`^v\.[0-9]+\.[0-9]+$`:          `^v\.\d+\.\d+$`,
+			`^(?:ab)+$`:                    `^(?:ab)+$`,
+			`^x|y$`:                        `^x|y$`,
+			`^[^/]+$`:                      `^[^/]+$`,
+			`^\S+$`:                        `^[^\t\n\f\r ]+$`,
+		}
+		for re2, ecma := range cases {
+			translated, err := translateRegex(re2)
+			require.NoError(t, err, re2)
+			assert.Equal(t, ecma, translated, re2)
+		}
+	})
+
+	t.Run("translated patterns match the same strings", func(t *testing.T) {
+		patterns := []string{`^[a-z][a-z0-9-]{2,62}$`, `^(?i)prod-[a-z]+$`, `^[^_]+_[0-9]+$`}
+		inputs := []string{"prod-web", "PROD-Web", "Prod-", "abc", "my-bucket-01", "x_1", "x_", "_1", "a-b"}
+
+		for _, pattern := range patterns {
+			translated, err := translateRegex(pattern)
+			require.NoError(t, err)
+
+			// The ECMA output is also valid RE2, so both can be compared
+			original := regexp.MustCompile(pattern)
+			ecma := regexp.MustCompile(translated)
+			for _, input := range inputs {
+				assert.Equal(t, original.MatchString(input), ecma.MatchString(input), "%s vs %s on %q", pattern, translated, input)
+			}
+		}
+	})
+
+	t.Run("case-insensitive flag is expanded", func(t *testing.T) {
+		translated, err := translateRegex(`(?i)ab1`)
+		require.NoError(t, err)
+		assert.Equal(t, `[Aa][Bb]1`, translated)
+	})
+
+	t.Run("dot-all flag", func(t *testing.T) {
+		translated, err := translateRegex(`(?s)^a.b$`)
+		require.NoError(t, err)
+		assert.Equal(t, `^a[\s\S]b$`, translated)
+	})
+
+	t.Run("named groups become plain groups", func(t *testing.T) {
+		translated, err := translateRegex(`^(?P<env>dev|prod)-\d+$`)
+		require.NoError(t, err)
+		assert.Equal(t, `^(dev|prod)-\d+$`, translated)
+	})
+
+	t.Run("untranslatable patterns", func(t *testing.T) {
+		for _, pattern := range []string{
+			`(?m)^foo$`,
+			`^\p{Greek}+$`,
+			`^[\x{1F600}-\x{1F64F}]$`,
+			`[a-`,
+		} {
+			_, err := translateRegex(pattern)
+			assert.Error(t, err, pattern)
+		}
+	})
+}
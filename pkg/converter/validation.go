@@ -0,0 +1,523 @@
+package converter
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+
+	"github.com/samart/terraform-schema-generator/pkg/parser"
+)
+
+// applyValidationRules translates the validation blocks of a variable into
+// JSON Schema keywords. Each condition is parsed as an HCL expression and
+// split on && so that every conjunct can be recognised on its own. Every
+// rule is also recorded in x-terraform-validations, marked as translated
+// when the keywords fully express it and listing the keywords it produced
+func (c *Converter) applyValidationRules(property *Property, variable parser.Variable) {
+	for _, rule := range variable.Validations {
+		record := ValidationRule{
+			Condition:    rule.Condition,
+			ErrorMessage: rule.ErrorMessage,
+		}
+
+		expr, diags := hclsyntax.ParseExpression([]byte(rule.Condition), rule.Range.Filename, hcl.InitialPos)
+		if !diags.HasErrors() {
+			record.Translated = true
+			for _, term := range conjuncts(expr) {
+				// A null check only exempts null, which keywords for other
+				// types already accept, from the rest of the condition
+				guarded, isGuarded := nullGuarded(term, variable.Name)
+				for _, subterm := range conjuncts(guarded) {
+					keywords, ok := c.applyRegexCondition(property, variable, rule, subterm)
+					if !ok {
+						keywords, ok = c.applyEnumCondition(property, variable, subterm, isGuarded && variable.Nullable)
+					}
+					if !ok {
+						keywords, ok = c.applyBoundCondition(property, variable, subterm)
+					}
+					if !ok {
+						record.Translated = false
+					}
+					record.addKeywords(keywords...)
+				}
+			}
+		}
+
+		property.Validations = append(property.Validations, record)
+	}
+}
+
+// applyRegexCondition sets a pattern for conditions that require the
+// variable to match a regular expression, such as
+//
+//	can(regex("^[a-z0-9-]+$", var.name))
+//	length(regexall("^[a-z0-9-]+$", var.name)) > 0
+//
+// and returns the keywords set, or false when the condition was not expressed
+func (c *Converter) applyRegexCondition(property *Property, variable parser.Variable, rule parser.Validation, expr hclsyntax.Expression) ([]string, bool) {
+	pattern, ok := regexConditionPattern(expr, variable.Name)
+	if !ok || property.Type != "string" {
+		return nil, false
+	}
+
+	translated, err := translateRegex(pattern)
+	if err != nil {
+		c.warn(
+			"Validation pattern not translated",
+			fmt.Sprintf("The regular expression %q in a validation rule for variable %q cannot be expressed as a JSON Schema pattern: %s. The rule is only kept in x-terraform-validations.", pattern, variable.Name, err),
+			rule.Range,
+		)
+		return nil, false
+	}
+
+	// JSON Schema allows a single pattern per schema, so further patterns
+	// must all match as well
+	if property.Pattern == "" {
+		property.Pattern = translated
+		return []string{"pattern"}, true
+	}
+	property.AllOf = append(property.AllOf, Property{Pattern: translated})
+	return []string{fmt.Sprintf("allOf/%d/pattern", len(property.AllOf)-1)}, true
+}
+
+// applyEnumCondition restricts a property to the values listed in
+// conditions such as
+//
+//	contains(["dev", "staging", "prod"], var.environment)
+//	alltrue([for az in var.zones : contains(["a", "b", "c"], az)])
+//
+// where the second form applies to the elements of a collection. With
+// allowNull, the enum also admits null, as for conditions behind a null
+// check. It returns the keywords set, or false when the condition was not
+// expressed
+func (c *Converter) applyEnumCondition(property *Property, variable parser.Variable, expr hclsyntax.Expression, allowNull bool) ([]string, bool) {
+	spec := variableTypeSpec(variable)
+
+	if values, ok := containsValues(expr, func(subject hclsyntax.Expression) bool {
+		return isVariableReference(subject, variable.Name)
+	}); ok {
+		if allowNull {
+			values = append(values, cty.NullVal(cty.DynamicPseudoType))
+		}
+		restrictEnum(property, values, spec)
+		return []string{"enum"}, true
+	}
+
+	// Element-wise checks need a collection to attach the enum to
+	if spec == nil || spec.Element == nil {
+		return nil, false
+	}
+	var target *Property
+	var location string
+	switch spec.Kind {
+	case parser.TypeList, parser.TypeSet:
+		target, _ = property.Items.(*Property)
+		location = "items"
+	case parser.TypeMap:
+		target, _ = property.AdditionalProperties.(*Property)
+		location = "additionalProperties"
+	}
+	if target == nil {
+		return nil, false
+	}
+
+	call, ok := unwrapParens(expr).(*hclsyntax.FunctionCallExpr)
+	if !ok || call.Name != "alltrue" || len(call.Args) != 1 {
+		return nil, false
+	}
+	loop, ok := unwrapParens(call.Args[0]).(*hclsyntax.ForExpr)
+	if !ok || loop.KeyExpr != nil || loop.CondExpr != nil || !isVariableReference(loop.CollExpr, variable.Name) {
+		return nil, false
+	}
+	if values, ok := containsValues(loop.ValExpr, func(subject hclsyntax.Expression) bool {
+		return isLocalReference(subject, loop.ValVar)
+	}); ok {
+		restrictEnum(target, values, spec.Element)
+		return []string{location + "/enum"}, true
+	}
+	return nil, false
+}
+
+// applyBoundCondition sets numeric or length limits for comparisons of the
+// variable, or of its length, against a constant, such as
+//
+//	var.port >= 1
+//	length(var.name) <= 63
+//
+// Which keywords the limit maps to depends on the declared type. It returns
+// the keywords set, or false when the condition was not expressed
+func (c *Converter) applyBoundCondition(property *Property, variable parser.Variable, expr hclsyntax.Expression) ([]string, bool) {
+	spec := variableTypeSpec(variable)
+	if spec == nil {
+		return nil, false
+	}
+
+	op, ok := unwrapParens(expr).(*hclsyntax.BinaryOpExpr)
+	if !ok {
+		return nil, false
+	}
+	subject, operation := op.LHS, op.Op
+	limit, ok := literalNumber(op.RHS)
+	if !ok {
+		// Constant on the left, as in 0 < var.count
+		if limit, ok = literalNumber(op.LHS); !ok {
+			return nil, false
+		}
+		subject, operation = op.RHS, flipComparison(op.Op)
+	}
+
+	if isVariableReference(subject, variable.Name) {
+		if spec.Kind != parser.TypeNumber || !applyNumericBound(property, operation, limit) {
+			return nil, false
+		}
+		return numericBoundKeywords(operation), true
+	}
+
+	call, ok := unwrapParens(subject).(*hclsyntax.FunctionCallExpr)
+	if !ok || call.Name != "length" || len(call.Args) != 1 || !isVariableReference(call.Args[0], variable.Name) {
+		return nil, false
+	}
+	var lower, upper string
+	switch spec.Kind {
+	case parser.TypeString:
+		ok = applyLengthBound(&property.MinLength, &property.MaxLength, operation, limit)
+		lower, upper = "minLength", "maxLength"
+	case parser.TypeList, parser.TypeSet:
+		ok = applyLengthBound(&property.MinItems, &property.MaxItems, operation, limit)
+		lower, upper = "minItems", "maxItems"
+	case parser.TypeMap:
+		ok = applyLengthBound(&property.MinProperties, &property.MaxProperties, operation, limit)
+		lower, upper = "minProperties", "maxProperties"
+	default:
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+
+	switch operation {
+	case hclsyntax.OpGreaterThan, hclsyntax.OpGreaterThanOrEqual:
+		return []string{lower}, true
+	case hclsyntax.OpLessThan, hclsyntax.OpLessThanOrEqual:
+		return []string{upper}, true
+	}
+	return []string{lower, upper}, true
+}
+
+// flipComparison returns the operator that gives the same result with its
+// operands swapped, or nil for operators that are not comparisons
+func flipComparison(op *hclsyntax.Operation) *hclsyntax.Operation {
+	switch op {
+	case hclsyntax.OpGreaterThan:
+		return hclsyntax.OpLessThan
+	case hclsyntax.OpGreaterThanOrEqual:
+		return hclsyntax.OpLessThanOrEqual
+	case hclsyntax.OpLessThan:
+		return hclsyntax.OpGreaterThan
+	case hclsyntax.OpLessThanOrEqual:
+		return hclsyntax.OpGreaterThanOrEqual
+	case hclsyntax.OpEqual:
+		return hclsyntax.OpEqual
+	}
+	return nil
+}
+
+// applyNumericBound narrows the minimum and maximum of a number property.
+// Strict comparisons map to the exclusive keywords. It reports whether the
+// operator is a comparison that could be expressed
+func applyNumericBound(property *Property, op *hclsyntax.Operation, limit float64) bool {
+	switch op {
+	case hclsyntax.OpGreaterThan:
+		tightenBound(&property.Minimum, &property.ExclusiveMinimum, limit, true, 1)
+	case hclsyntax.OpGreaterThanOrEqual:
+		tightenBound(&property.Minimum, &property.ExclusiveMinimum, limit, false, 1)
+	case hclsyntax.OpLessThan:
+		tightenBound(&property.Maximum, &property.ExclusiveMaximum, limit, true, -1)
+	case hclsyntax.OpLessThanOrEqual:
+		tightenBound(&property.Maximum, &property.ExclusiveMaximum, limit, false, -1)
+	case hclsyntax.OpEqual:
+		tightenBound(&property.Minimum, &property.ExclusiveMinimum, limit, false, 1)
+		tightenBound(&property.Maximum, &property.ExclusiveMaximum, limit, false, -1)
+	default:
+		return false
+	}
+	return true
+}
+
+// numericBoundKeywords returns the keywords a comparison of a number sets
+func numericBoundKeywords(op *hclsyntax.Operation) []string {
+	switch op {
+	case hclsyntax.OpGreaterThan:
+		return []string{"exclusiveMinimum"}
+	case hclsyntax.OpGreaterThanOrEqual:
+		return []string{"minimum"}
+	case hclsyntax.OpLessThan:
+		return []string{"exclusiveMaximum"}
+	case hclsyntax.OpLessThanOrEqual:
+		return []string{"maximum"}
+	}
+	return []string{"minimum", "maximum"}
+}
+
+// tightenBound replaces an inclusive or exclusive bound when the new limit
+// is stricter, so that several conditions only accept values allowed by
+// all. direction is 1 for lower bounds and -1 for upper bounds
+func tightenBound(inclusive, exclusive **float64, limit float64, strict bool, direction float64) {
+	switch {
+	case *exclusive != nil:
+		if limit*direction <= **exclusive*direction {
+			return
+		}
+	case *inclusive != nil:
+		if limit*direction < **inclusive*direction || (limit == **inclusive && !strict) {
+			return
+		}
+	}
+
+	value := limit
+	if strict {
+		*inclusive, *exclusive = nil, &value
+	} else {
+		*inclusive, *exclusive = &value, nil
+	}
+}
+
+// applyLengthBound narrows the minimum and maximum of a length keyword.
+// Lengths are whole numbers, so strict and fractional limits are rounded
+// to the nearest length that satisfies the comparison. It reports whether
+// the comparison could be expressed
+func applyLengthBound(minimum, maximum **int, op *hclsyntax.Operation, limit float64) bool {
+	lower, upper := 0, math.MaxInt
+	switch op {
+	case hclsyntax.OpGreaterThan:
+		lower = int(math.Floor(limit)) + 1
+	case hclsyntax.OpGreaterThanOrEqual:
+		lower = int(math.Ceil(limit))
+	case hclsyntax.OpLessThan:
+		upper = int(math.Ceil(limit)) - 1
+	case hclsyntax.OpLessThanOrEqual:
+		upper = int(math.Floor(limit))
+	case hclsyntax.OpEqual:
+		if limit != math.Trunc(limit) {
+			return false
+		}
+		lower, upper = int(limit), int(limit)
+	default:
+		return false
+	}
+
+	// No length is negative, so such an upper bound cannot be written as
+	// a keyword, while a lower bound of zero or less holds for every value
+	if upper < 0 {
+		return false
+	}
+	if lower > 0 && (*minimum == nil || lower > **minimum) {
+		*minimum = &lower
+	}
+	if upper != math.MaxInt && (*maximum == nil || upper < **maximum) {
+		*maximum = &upper
+	}
+	return true
+}
+
+// containsValues returns the literal list of a contains(list, value) call
+// whose value is accepted by isSubject
+func containsValues(expr hclsyntax.Expression, isSubject func(hclsyntax.Expression) bool) ([]cty.Value, bool) {
+	call, ok := unwrapParens(expr).(*hclsyntax.FunctionCallExpr)
+	if !ok || call.Name != "contains" || len(call.Args) != 2 || !isSubject(call.Args[1]) {
+		return nil, false
+	}
+
+	list, diags := call.Args[0].Value(nil)
+	if diags.HasErrors() || !list.IsWhollyKnown() || list.IsNull() {
+		return nil, false
+	}
+	if !list.Type().IsListType() && !list.Type().IsTupleType() && !list.Type().IsSetType() {
+		return nil, false
+	}
+	return list.AsValueSlice(), true
+}
+
+// restrictEnum limits a property to values, converted to the declared type
+// so that e.g. numbers written as strings compare correctly. A property
+// restricted by several conditions only accepts values allowed by all
+func restrictEnum(property *Property, values []cty.Value, spec *parser.TypeSpec) {
+	enum := []interface{}{}
+	for _, val := range values {
+		if spec != nil && spec.IsPrimitive() {
+			converted, err := convert.Convert(val, spec.CtyType())
+			if err != nil {
+				// Terraform would never match this value either
+				continue
+			}
+			val = converted
+		}
+
+		goVal, err := parser.ConvertCtyValue(val)
+		if err != nil || containsValue(enum, goVal) {
+			continue
+		}
+		enum = append(enum, goVal)
+	}
+
+	if property.Enum != nil {
+		intersection := []interface{}{}
+		for _, v := range property.Enum {
+			if containsValue(enum, v) {
+				intersection = append(intersection, v)
+			}
+		}
+		enum = intersection
+	}
+	property.Enum = enum
+}
+
+// containsValue reports whether values includes v
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, existing := range values {
+		if reflect.DeepEqual(existing, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// regexConditionPattern returns the regular expression a condition
+// requires the named variable to match
+func regexConditionPattern(expr hclsyntax.Expression, name string) (string, bool) {
+	switch e := unwrapParens(expr).(type) {
+	case *hclsyntax.FunctionCallExpr:
+		// can(regex(pattern, var.name))
+		if e.Name != "can" || len(e.Args) != 1 {
+			return "", false
+		}
+		return regexCallPattern(e.Args[0], "regex", name)
+
+	case *hclsyntax.BinaryOpExpr:
+		// length(regexall(pattern, var.name)) > 0, >= 1 or != 0
+		call, ok := unwrapParens(e.LHS).(*hclsyntax.FunctionCallExpr)
+		if !ok || call.Name != "length" || len(call.Args) != 1 {
+			return "", false
+		}
+		limit, ok := literalNumber(e.RHS)
+		if !ok {
+			return "", false
+		}
+		switch {
+		case e.Op == hclsyntax.OpGreaterThan && limit == 0,
+			e.Op == hclsyntax.OpGreaterThanOrEqual && limit == 1,
+			e.Op == hclsyntax.OpNotEqual && limit == 0:
+			return regexCallPattern(call.Args[0], "regexall", name)
+		}
+	}
+
+	return "", false
+}
+
+// regexCallPattern returns the literal pattern of a regex or regexall call
+// applied directly to the named variable
+func regexCallPattern(expr hclsyntax.Expression, function, name string) (string, bool) {
+	call, ok := unwrapParens(expr).(*hclsyntax.FunctionCallExpr)
+	if !ok || call.Name != function || len(call.Args) != 2 {
+		return "", false
+	}
+	if !isVariableReference(call.Args[1], name) {
+		return "", false
+	}
+	return literalString(call.Args[0])
+}
+
+// conjuncts splits an expression on the && operator
+func conjuncts(expr hclsyntax.Expression) []hclsyntax.Expression {
+	if op, ok := unwrapParens(expr).(*hclsyntax.BinaryOpExpr); ok && op.Op == hclsyntax.OpLogicalAnd {
+		return append(conjuncts(op.LHS), conjuncts(op.RHS)...)
+	}
+	return []hclsyntax.Expression{expr}
+}
+
+// nullGuarded returns the condition of expressions such as
+//
+//	var.name == null || can(regex("^[a-z]+$", var.name))
+//
+// which only apply it to non-null values
+func nullGuarded(expr hclsyntax.Expression, name string) (hclsyntax.Expression, bool) {
+	op, ok := unwrapParens(expr).(*hclsyntax.BinaryOpExpr)
+	if !ok || op.Op != hclsyntax.OpLogicalOr {
+		return expr, false
+	}
+	if isNullCheck(op.LHS, name) {
+		return op.RHS, true
+	}
+	if isNullCheck(op.RHS, name) {
+		return op.LHS, true
+	}
+	return expr, false
+}
+
+// isNullCheck reports whether expr is var.<name> == null
+func isNullCheck(expr hclsyntax.Expression, name string) bool {
+	op, ok := unwrapParens(expr).(*hclsyntax.BinaryOpExpr)
+	if !ok || op.Op != hclsyntax.OpEqual {
+		return false
+	}
+	return (isVariableReference(op.LHS, name) && isNullLiteral(op.RHS)) ||
+		(isVariableReference(op.RHS, name) && isNullLiteral(op.LHS))
+}
+
+// isNullLiteral reports whether expr is the null keyword
+func isNullLiteral(expr hclsyntax.Expression) bool {
+	val, diags := expr.Value(nil)
+	return !diags.HasErrors() && val.IsNull()
+}
+
+// unwrapParens removes any enclosing parentheses from an expression
+func unwrapParens(expr hclsyntax.Expression) hclsyntax.Expression {
+	for {
+		parens, ok := expr.(*hclsyntax.ParenthesesExpr)
+		if !ok {
+			return expr
+		}
+		expr = parens.Expression
+	}
+}
+
+// isVariableReference reports whether expr is exactly var.<name>
+func isVariableReference(expr hclsyntax.Expression, name string) bool {
+	traversal, ok := unwrapParens(expr).(*hclsyntax.ScopeTraversalExpr)
+	if !ok || len(traversal.Traversal) != 2 || traversal.Traversal.RootName() != "var" {
+		return false
+	}
+	attr, ok := traversal.Traversal[1].(hcl.TraverseAttr)
+	return ok && attr.Name == name
+}
+
+// isLocalReference reports whether expr refers to a local symbol such as
+// the value variable of a for expression
+func isLocalReference(expr hclsyntax.Expression, name string) bool {
+	traversal, ok := unwrapParens(expr).(*hclsyntax.ScopeTraversalExpr)
+	return ok && len(traversal.Traversal) == 1 && traversal.Traversal.RootName() == name
+}
+
+// literalString evaluates an expression that must be a constant string
+func literalString(expr hclsyntax.Expression) (string, bool) {
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() || val.Type() != cty.String {
+		return "", false
+	}
+	return val.AsString(), true
+}
+
+// literalNumber evaluates an expression that must be a constant number
+func literalNumber(expr hclsyntax.Expression) (float64, bool) {
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() || val.Type() != cty.Number {
+		return 0, false
+	}
+	f, _ := val.AsBigFloat().Float64()
+	return f, true
+}
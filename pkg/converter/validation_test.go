@@ -0,0 +1,675 @@
+package converter
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/samart/terraform-schema-generator/pkg/parser"
+)
+
+// convertSource parses Terraform source and converts it to a schema
+func convertSource(t *testing.T, tfContent string) (*JSONSchema7, *Converter) {
+	t.Helper()
+
+	parseResult, err := parser.NewParser().ParseFiles(map[string]io.Reader{
+		"variables.tf": strings.NewReader(tfContent),
+	})
+	require.NoError(t, err)
+
+	converter := NewConverter()
+	schema, err := converter.ConvertToJSONSchema7(parseResult)
+	require.NoError(t, err)
+	return schema, converter
+}
+
+func TestRegexValidation(t *testing.T) {
+	t.Run("can(regex(...)) becomes a pattern", func(t *testing.T) {
+		schema, converter := convertSource(t, `
+variable "name" {
+  type = string
+  validation {
+    condition     = can(regex("^[a-z0-9]+$", var.name))
+    error_message = "Only lowercase letters and digits."
+  }
+}
+`)
+		assert.Equal(t, `^[0-9a-z]+$`, schema.Properties["name"].Pattern)
+		assert.Empty(t, converter.Diagnostics())
+		validateSchemaAgainstMetaSchema(t, schema)
+	})
+
+	t.Run("length(regexall(...)) > 0 becomes a pattern", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "bucket" {
+  type = string
+  validation {
+    condition     = length(regexall("^[a-z][a-z0-9.-]+$", var.bucket)) > 0
+    error_message = "Invalid bucket name."
+  }
+}
+`)
+		assert.Equal(t, `^[a-z][\-.0-9a-z]+$`, schema.Properties["bucket"].Pattern)
+	})
+
+	t.Run("pattern combined with other conditions", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "env" {
+  type = string
+  validation {
+    condition     = var.env != "" && (can(regex("^(dev|prod)", var.env)))
+    error_message = "Invalid environment."
+  }
+}
+`)
+		assert.Equal(t, `^(dev|prod)`, schema.Properties["env"].Pattern)
+	})
+
+	t.Run("several patterns must all match", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "id" {
+  type = string
+  validation {
+    condition     = can(regex("^[a-z]", var.id))
+    error_message = "Must start with a letter."
+  }
+  validation {
+    condition     = can(regex("[0-9]$", var.id))
+    error_message = "Must end with a digit."
+  }
+}
+`)
+		property := schema.Properties["id"]
+		assert.Equal(t, `^[a-z]`, property.Pattern)
+		require.Len(t, property.AllOf, 1)
+		assert.Equal(t, `\d$`, property.AllOf[0].Pattern)
+		assert.Equal(t, []string{"pattern"}, property.Validations[0].Keywords)
+		assert.Equal(t, []string{"allOf/0/pattern"}, property.Validations[1].Keywords)
+		validateSchemaAgainstMetaSchema(t, schema)
+	})
+
+	t.Run("conditions that do not require a match are ignored", func(t *testing.T) {
+		schema, converter := convertSource(t, `
+variable "name" {
+  type = string
+  validation {
+    condition     = !can(regex("^tmp-", var.name))
+    error_message = "Temporary names are not allowed."
+  }
+  validation {
+    condition     = can(regex("^[a-z]+$", var.other))
+    error_message = "References another variable."
+  }
+  validation {
+    condition     = can(regex("^[a-z]+$", var.name)) || var.name == ""
+    error_message = "Disjunctions cannot be expressed."
+  }
+}
+`)
+		assert.Empty(t, schema.Properties["name"].Pattern)
+		assert.Empty(t, schema.Properties["name"].AllOf)
+		assert.Empty(t, converter.Diagnostics())
+	})
+
+	t.Run("untranslatable pattern is left out with a warning", func(t *testing.T) {
+		schema, converter := convertSource(t, `
+variable "name" {
+  type = string
+  validation {
+    condition     = can(regex("(?m)^[a-z]+$", var.name))
+    error_message = "Lowercase only."
+  }
+}
+`)
+		assert.Empty(t, schema.Properties["name"].Pattern)
+
+		diags := converter.Diagnostics()
+		require.Len(t, diags, 1)
+		assert.Equal(t, parser.SeverityWarning, diags[0].Severity)
+		assert.Equal(t, "Validation pattern not translated", diags[0].Summary)
+		assert.Contains(t, diags[0].Detail, `"name"`)
+		require.NotNil(t, diags[0].Subject)
+		assert.Equal(t, "variables.tf", diags[0].Subject.Filename)
+		assert.Equal(t, 4, diags[0].Subject.Start.Line)
+	})
+
+	t.Run("patterns only apply to strings", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "ids" {
+  type = list(string)
+  validation {
+    condition     = can(regex("^[a-z]+$", var.ids))
+    error_message = "Not meaningful for a list."
+  }
+}
+`)
+		assert.Empty(t, schema.Properties["ids"].Pattern)
+	})
+
+	t.Run("diagnostics are reset between conversions", func(t *testing.T) {
+		converter := NewConverter()
+		result := &parser.ParseResult{
+			Variables: []parser.Variable{{
+				Name: "name",
+				Type: "string",
+				Validations: []parser.Validation{{
+					Condition: `can(regex("(?m)^x$", var.name))`,
+				}},
+			}},
+		}
+
+		_, err := converter.ConvertToJSONSchema7(result)
+		require.NoError(t, err)
+		assert.Len(t, converter.Diagnostics(), 1)
+		assert.Nil(t, converter.Diagnostics()[0].Subject)
+
+		_, err = converter.ConvertToJSONSchema7(result)
+		require.NoError(t, err)
+		assert.Len(t, converter.Diagnostics(), 1)
+	})
+}
+
+func TestEnumValidation(t *testing.T) {
+	t.Run("contains() becomes an enum", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "environment" {
+  type = string
+  validation {
+    condition     = contains(["dev", "staging", "prod"], var.environment)
+    error_message = "Unknown environment."
+  }
+}
+`)
+		assert.Equal(t, []interface{}{"dev", "staging", "prod"}, schema.Properties["environment"].Enum)
+		validateSchemaAgainstMetaSchema(t, schema)
+	})
+
+	t.Run("number and bool enums", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "port" {
+  type = number
+  validation {
+    condition     = contains([80, 443, "8080"], var.port)
+    error_message = "Unsupported port."
+  }
+}
+
+variable "flag" {
+  type = bool
+  validation {
+    condition     = contains([true], var.flag)
+    error_message = "Must be enabled."
+  }
+}
+`)
+		assert.Equal(t, []interface{}{json.Number("80"), json.Number("443"), json.Number("8080")}, schema.Properties["port"].Enum)
+		assert.Equal(t, []interface{}{true}, schema.Properties["flag"].Enum)
+
+		out, err := NewConverter().ToJSON(schema)
+		require.NoError(t, err)
+		assert.Contains(t, string(out), `"enum": [
+        80,
+        443,
+        8080
+      ]`)
+	})
+
+	t.Run("values that cannot match the declared type are dropped", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "size" {
+  type = number
+  validation {
+    condition     = contains([1, 2, "large"], var.size)
+    error_message = "Unsupported size."
+  }
+}
+`)
+		assert.Equal(t, []interface{}{json.Number("1"), json.Number("2")}, schema.Properties["size"].Enum)
+	})
+
+	t.Run("several conditions intersect", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "region" {
+  type = string
+  validation {
+    condition     = contains(["us-east-1", "us-west-2", "eu-west-1"], var.region) && var.region != ""
+    error_message = "Unsupported region."
+  }
+  validation {
+    condition     = contains(["eu-west-1", "us-east-1", "ap-south-1"], var.region)
+    error_message = "Region not approved."
+  }
+}
+`)
+		assert.Equal(t, []interface{}{"us-east-1", "eu-west-1"}, schema.Properties["region"].Enum)
+	})
+
+	t.Run("element-wise contains() on collections", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "zones" {
+  type = list(string)
+  validation {
+    condition     = alltrue([for z in var.zones : contains(["a", "b", "c"], z)])
+    error_message = "Unknown zone."
+  }
+}
+
+variable "tiers" {
+  type = map(string)
+  validation {
+    condition     = alltrue([for k, v in var.tiers : contains(["gold", "silver"], v)])
+    error_message = "Unknown tier."
+  }
+}
+`)
+		items, ok := schema.Properties["zones"].Items.(*Property)
+		require.True(t, ok)
+		assert.Equal(t, []interface{}{"a", "b", "c"}, items.Enum)
+		assert.Nil(t, schema.Properties["zones"].Enum)
+		assert.Equal(t, []string{"items/enum"}, schema.Properties["zones"].Validations[0].Keywords)
+
+		values, ok := schema.Properties["tiers"].AdditionalProperties.(*Property)
+		require.True(t, ok)
+		assert.Equal(t, []interface{}{"gold", "silver"}, values.Enum)
+		assert.Equal(t, []string{"additionalProperties/enum"}, schema.Properties["tiers"].Validations[0].Keywords)
+		validateSchemaAgainstMetaSchema(t, schema)
+	})
+
+	t.Run("conditions that do not restrict the variable are ignored", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "name" {
+  type = string
+  validation {
+    condition     = !contains(["admin", "root"], var.name)
+    error_message = "Reserved name."
+  }
+  validation {
+    condition     = contains(["a", "b"], var.name) || var.name == ""
+    error_message = "Disjunction."
+  }
+  validation {
+    condition     = contains(var.allowed, var.name)
+    error_message = "Not a literal list."
+  }
+}
+`)
+		assert.Nil(t, schema.Properties["name"].Enum)
+	})
+}
+
+func TestBoundsValidation(t *testing.T) {
+	t.Run("string length bounds", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "name" {
+  type = string
+  validation {
+    condition     = length(var.name) >= 3 && length(var.name) <= 63
+    error_message = "Between 3 and 63 characters."
+  }
+}
+`)
+		property := schema.Properties["name"]
+		require.NotNil(t, property.MinLength)
+		require.NotNil(t, property.MaxLength)
+		assert.Equal(t, 3, *property.MinLength)
+		assert.Equal(t, 63, *property.MaxLength)
+		validateSchemaAgainstMetaSchema(t, schema)
+	})
+
+	t.Run("strict length comparisons are rounded to whole lengths", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "name" {
+  type = string
+  validation {
+    condition     = length(var.name) > 0 && 64 > length(var.name)
+    error_message = "Between 1 and 63 characters."
+  }
+}
+`)
+		property := schema.Properties["name"]
+		require.NotNil(t, property.MinLength)
+		require.NotNil(t, property.MaxLength)
+		assert.Equal(t, 1, *property.MinLength)
+		assert.Equal(t, 63, *property.MaxLength)
+	})
+
+	t.Run("collection lengths", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "subnets" {
+  type = list(string)
+  validation {
+    condition     = length(var.subnets) >= 2
+    error_message = "At least two subnets."
+  }
+}
+
+variable "tags" {
+  type = map(string)
+  validation {
+    condition     = length(var.tags) <= 10
+    error_message = "At most ten tags."
+  }
+}
+
+variable "azs" {
+  type = set(string)
+  validation {
+    condition     = length(var.azs) == 3
+    error_message = "Exactly three zones."
+  }
+}
+`)
+		subnets := schema.Properties["subnets"]
+		require.NotNil(t, subnets.MinItems)
+		assert.Equal(t, 2, *subnets.MinItems)
+		assert.Nil(t, subnets.MinLength)
+
+		tags := schema.Properties["tags"]
+		require.NotNil(t, tags.MaxProperties)
+		assert.Equal(t, 10, *tags.MaxProperties)
+
+		azs := schema.Properties["azs"]
+		require.NotNil(t, azs.MinItems)
+		require.NotNil(t, azs.MaxItems)
+		assert.Equal(t, 3, *azs.MinItems)
+		assert.Equal(t, 3, *azs.MaxItems)
+		validateSchemaAgainstMetaSchema(t, schema)
+	})
+
+	t.Run("numeric bounds", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "port" {
+  type = number
+  validation {
+    condition     = var.port >= 1 && var.port <= 65535
+    error_message = "Invalid port."
+  }
+}
+
+variable "instance_count" {
+  type = number
+  validation {
+    condition     = var.instance_count > 0
+    error_message = "Must be positive."
+  }
+}
+
+variable "ratio" {
+  type = number
+  validation {
+    condition     = 1 > var.ratio
+    error_message = "Must be below one."
+  }
+}
+`)
+		port := schema.Properties["port"]
+		require.NotNil(t, port.Minimum)
+		require.NotNil(t, port.Maximum)
+		assert.Equal(t, 1.0, *port.Minimum)
+		assert.Equal(t, 65535.0, *port.Maximum)
+
+		count := schema.Properties["instance_count"]
+		assert.Nil(t, count.Minimum)
+		require.NotNil(t, count.ExclusiveMinimum)
+		assert.Equal(t, 0.0, *count.ExclusiveMinimum)
+
+		ratio := schema.Properties["ratio"]
+		require.NotNil(t, ratio.ExclusiveMaximum)
+		assert.Equal(t, 1.0, *ratio.ExclusiveMaximum)
+		validateSchemaAgainstMetaSchema(t, schema)
+	})
+
+	t.Run("the strictest of several bounds is kept", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "size" {
+  type = number
+  validation {
+    condition     = var.size >= 10 && var.size > 10
+    error_message = "Above ten."
+  }
+  validation {
+    condition     = var.size >= 5 && var.size < 100
+    error_message = "Below one hundred."
+  }
+  validation {
+    condition     = var.size <= 50
+    error_message = "At most fifty."
+  }
+}
+`)
+		property := schema.Properties["size"]
+		assert.Nil(t, property.Minimum)
+		require.NotNil(t, property.ExclusiveMinimum)
+		assert.Equal(t, 10.0, *property.ExclusiveMinimum)
+		assert.Nil(t, property.ExclusiveMaximum)
+		require.NotNil(t, property.Maximum)
+		assert.Equal(t, 50.0, *property.Maximum)
+	})
+
+	t.Run("bounds follow the declared type", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "name" {
+  type = string
+  validation {
+    condition     = var.name > 5
+    error_message = "Not a meaningful string comparison."
+  }
+}
+
+variable "port" {
+  type = number
+  validation {
+    condition     = length(var.port) > 2
+    error_message = "Not valid for a number."
+  }
+}
+
+variable "anything" {
+  validation {
+    condition     = length(var.anything) > 2
+    error_message = "The type is unknown."
+  }
+}
+`)
+		assert.Nil(t, schema.Properties["name"].Minimum)
+		assert.Nil(t, schema.Properties["name"].ExclusiveMinimum)
+		assert.Nil(t, schema.Properties["port"].MinLength)
+		assert.Nil(t, schema.Properties["anything"].MinLength)
+		assert.Nil(t, schema.Properties["anything"].MinItems)
+	})
+
+	t.Run("conditions that do not bound the variable are ignored", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "name" {
+  type = string
+  validation {
+    condition     = length(var.name) > 3 || var.name == ""
+    error_message = "Disjunction."
+  }
+  validation {
+    condition     = length(var.other) <= 10
+    error_message = "Another variable."
+  }
+  validation {
+    condition     = length(var.name) <= var.limit
+    error_message = "Not a constant."
+  }
+}
+`)
+		assert.Nil(t, schema.Properties["name"].MinLength)
+		assert.Nil(t, schema.Properties["name"].MaxLength)
+	})
+}
+
+func TestValidationExtensions(t *testing.T) {
+	t.Run("every rule is recorded with its error message", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "name" {
+  type = string
+  validation {
+    condition     = length(var.name) <= 63 && can(regex("^[a-z-]+$", var.name))
+    error_message = "Lowercase letters and dashes, at most 63 characters."
+  }
+  validation {
+    condition     = !startswith(var.name, "aws-")
+    error_message = "The aws- prefix is reserved."
+  }
+  validation {
+    condition     = can(regex("(?m)^[a-z]+$", var.name))
+    error_message = "Multi-line anchors."
+  }
+}
+`)
+		assert.Equal(t, []ValidationRule{
+			{
+				Condition:    `length(var.name) <= 63 && can(regex("^[a-z-]+$", var.name))`,
+				ErrorMessage: "Lowercase letters and dashes, at most 63 characters.",
+				Translated:   true,
+				Keywords:     []string{"maxLength", "pattern"},
+			},
+			{
+				Condition:    `!startswith(var.name, "aws-")`,
+				ErrorMessage: "The aws- prefix is reserved.",
+			},
+			{
+				Condition:    `can(regex("(?m)^[a-z]+$", var.name))`,
+				ErrorMessage: "Multi-line anchors.",
+			},
+		}, schema.Properties["name"].Validations)
+		validateSchemaAgainstMetaSchema(t, schema)
+	})
+
+	t.Run("partially translated rules are not marked as translated", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "port" {
+  type = number
+  validation {
+    condition     = var.port > 0 && var.port != 22
+    error_message = "Any positive port except SSH."
+  }
+}
+`)
+		property := schema.Properties["port"]
+		require.NotNil(t, property.ExclusiveMinimum)
+		require.Len(t, property.Validations, 1)
+		assert.False(t, property.Validations[0].Translated)
+		assert.Equal(t, []string{"exclusiveMinimum"}, property.Validations[0].Keywords)
+	})
+
+	t.Run("serialized as a schema extension", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "env" {
+  type = string
+  validation {
+    condition     = contains(["dev", "prod"], var.env)
+    error_message = "Unknown environment."
+  }
+}
+`)
+		out, err := NewConverter().ToJSON(schema)
+		require.NoError(t, err)
+		assert.Contains(t, string(out), `"x-terraform-validations": [
+        {
+          "condition": "contains([\"dev\", \"prod\"], var.env)",
+          "error_message": "Unknown environment.",
+          "translated": true,
+          "keywords": [
+            "enum"
+          ]
+        }
+      ]`)
+	})
+
+	t.Run("variables without validations have no extension", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "plain" {
+  type = string
+}
+`)
+		assert.Nil(t, schema.Properties["plain"].Validations)
+
+		out, err := NewConverter().ToJSON(schema)
+		require.NoError(t, err)
+		assert.NotContains(t, string(out), "x-terraform-validations")
+	})
+}
+
+func TestNullableValidation(t *testing.T) {
+	t.Run("explicit null is accepted by nullable variables", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "name" {
+  type    = string
+  default = null
+}
+
+variable "size" {
+  type     = number
+  nullable = false
+}
+`)
+		out, err := NewConverter().ToJSON(schema)
+		require.NoError(t, err)
+
+		validate := func(document string) bool {
+			result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(out), gojsonschema.NewStringLoader(document))
+			require.NoError(t, err)
+			return result.Valid()
+		}
+		assert.True(t, validate(`{"name": null, "size": 1}`))
+		assert.False(t, validate(`{"name": "x", "size": null}`))
+	})
+
+	t.Run("null checks admit null to the enum", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "tier" {
+  type    = string
+  default = null
+  validation {
+    condition     = var.tier == null || contains(["gold", "silver"], var.tier)
+    error_message = "Unknown tier."
+  }
+}
+
+variable "strict_tier" {
+  type = string
+  validation {
+    condition     = contains(["gold", "silver"], var.strict_tier)
+    error_message = "Unknown tier."
+  }
+}
+`)
+		tier := schema.Properties["tier"]
+		assert.Equal(t, []interface{}{"gold", "silver", nil}, tier.Enum)
+		require.Len(t, tier.Validations, 1)
+		assert.True(t, tier.Validations[0].Translated)
+
+		// Terraform evaluates the condition for null as well, so without
+		// the check null is rejected
+		assert.Equal(t, []interface{}{"gold", "silver"}, schema.Properties["strict_tier"].Enum)
+		validateSchemaAgainstMetaSchema(t, schema)
+	})
+
+	t.Run("null checks around other conditions", func(t *testing.T) {
+		schema, _ := convertSource(t, `
+variable "name" {
+  type    = string
+  default = null
+  validation {
+    condition     = (null == var.name) || (length(var.name) >= 3 && can(regex("^[a-z]+$", var.name)))
+    error_message = "At least three lowercase letters."
+  }
+}
+`)
+		property := schema.Properties["name"]
+		require.NotNil(t, property.MinLength)
+		assert.Equal(t, 3, *property.MinLength)
+		assert.Equal(t, `^[a-z]+$`, property.Pattern)
+		assert.Nil(t, property.Enum)
+		assert.True(t, property.Validations[0].Translated)
+	})
+}
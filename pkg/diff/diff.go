@@ -0,0 +1,614 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/samart/terraform-schema-generator/pkg/converter"
+	"github.com/samart/terraform-schema-generator/pkg/generator"
+	"github.com/samart/terraform-schema-generator/pkg/parser"
+)
+
+// ChangeKind identifies what changed between two versions of a module
+type ChangeKind string
+
+// Kinds of changes detected between module versions
+const (
+	VariableAdded      ChangeKind = "variable_added"
+	VariableRemoved    ChangeKind = "variable_removed"
+	AttributeAdded     ChangeKind = "attribute_added"
+	AttributeRemoved   ChangeKind = "attribute_removed"
+	TypeNarrowed       ChangeKind = "type_narrowed"
+	TypeWidened        ChangeKind = "type_widened"
+	EnumValueRemoved   ChangeKind = "enum_value_removed"
+	EnumValueAdded     ChangeKind = "enum_value_added"
+	BoundsTightened    ChangeKind = "bounds_tightened"
+	BoundsRelaxed      ChangeKind = "bounds_relaxed"
+	PatternChanged     ChangeKind = "pattern_changed"
+	DefaultRemoved     ChangeKind = "default_removed"
+	DefaultAdded       ChangeKind = "default_added"
+	DefaultChanged     ChangeKind = "default_changed"
+	SensitiveChanged   ChangeKind = "sensitive_changed"
+	DescriptionChanged ChangeKind = "description_changed"
+	OutputAdded        ChangeKind = "output_added"
+	OutputRemoved      ChangeKind = "output_removed"
+)
+
+// Change is a single difference between two module versions
+type Change struct {
+	Kind ChangeKind `json:"kind"`
+
+	// Path locates the change, such as var.name, var.ports[].protocol or
+	// output.id. Element types of lists and sets are written [] and the
+	// value type of maps {}
+	Path string `json:"path"`
+
+	// Breaking is set when inputs that were valid for the old version may
+	// be rejected by the new one, or consumers may lose something they use
+	Breaking bool   `json:"breaking"`
+	Message  string `json:"message"`
+}
+
+// String formats the change with its location
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s", c.Path, c.Message)
+}
+
+// Bump is a semantic version increment
+type Bump string
+
+// Semantic version increments, from least to most significant
+const (
+	BumpNone  Bump = "none"
+	BumpPatch Bump = "patch"
+	BumpMinor Bump = "minor"
+	BumpMajor Bump = "major"
+)
+
+// Report lists the changes between two module versions
+type Report struct {
+	Changes []Change `json:"changes"`
+}
+
+// Breaking returns the breaking changes
+func (r *Report) Breaking() []Change {
+	return r.filter(true)
+}
+
+// NonBreaking returns the changes that existing consumers can adopt as is
+func (r *Report) NonBreaking() []Change {
+	return r.filter(false)
+}
+
+// HasBreaking reports whether any change is breaking
+func (r *Report) HasBreaking() bool {
+	return len(r.Breaking()) > 0
+}
+
+// SuggestedBump returns the semantic version increment the changes call
+// for: major for breaking changes, minor for new inputs, outputs or other
+// behavior, and patch when only descriptions changed
+func (r *Report) SuggestedBump() Bump {
+	bump := BumpNone
+	for _, change := range r.Changes {
+		switch {
+		case change.Breaking:
+			return BumpMajor
+		case change.Kind == DescriptionChanged:
+			if bump == BumpNone {
+				bump = BumpPatch
+			}
+		default:
+			bump = BumpMinor
+		}
+	}
+	return bump
+}
+
+// WriteReport prints the breaking and non-breaking changes, one per line,
+// followed by the suggested version bump
+func WriteReport(w io.Writer, report *Report) error {
+	var buf bytes.Buffer
+	if len(report.Changes) == 0 {
+		buf.WriteString("No changes.\n")
+	}
+	if breaking := report.Breaking(); len(breaking) > 0 {
+		buf.WriteString("Breaking changes:\n")
+		for _, change := range breaking {
+			fmt.Fprintf(&buf, "  ✗ %s\n", change)
+		}
+	}
+	if nonBreaking := report.NonBreaking(); len(nonBreaking) > 0 {
+		buf.WriteString("Non-breaking changes:\n")
+		for _, change := range nonBreaking {
+			fmt.Fprintf(&buf, "  • %s\n", change)
+		}
+	}
+	fmt.Fprintf(&buf, "\nSuggested version bump: %s\n", report.SuggestedBump())
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (r *Report) filter(breaking bool) []Change {
+	var changes []Change
+	for _, change := range r.Changes {
+		if change.Breaking == breaking {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// CompareDirectories parses and converts the modules in two directories and
+// compares them
+func CompareDirectories(oldDir, newDir string) (*Report, error) {
+	oldResult, err := generator.New(generator.WithStrict(true)).FromDirectory(oldDir).Parse().ParseResult()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", oldDir, err)
+	}
+	newResult, err := generator.New(generator.WithStrict(true)).FromDirectory(newDir).Parse().ParseResult()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", newDir, err)
+	}
+	return CompareModules(oldResult, newResult), nil
+}
+
+// CompareModules compares the variables and outputs of two parsed modules
+func CompareModules(oldResult, newResult *parser.ParseResult) *Report {
+	report := CompareSchemas(moduleSchema(oldResult), moduleSchema(newResult))
+
+	newOutputs := make(map[string]parser.Output, len(newResult.Outputs))
+	for _, output := range newResult.Outputs {
+		newOutputs[output.Name] = output
+	}
+	oldOutputs := make(map[string]parser.Output, len(oldResult.Outputs))
+	for _, output := range oldResult.Outputs {
+		oldOutputs[output.Name] = output
+
+		path := "output." + output.Name
+		updated, exists := newOutputs[output.Name]
+		switch {
+		case !exists:
+			report.add(OutputRemoved, path, true, "output removed")
+		case !output.Sensitive && updated.Sensitive:
+			report.add(SensitiveChanged, path, true, "output is now sensitive")
+		case output.Sensitive && !updated.Sensitive:
+			report.add(SensitiveChanged, path, false, "output is no longer sensitive")
+		}
+		if exists && output.Description != updated.Description {
+			report.add(DescriptionChanged, path, false, "description changed")
+		}
+	}
+	for _, output := range newResult.Outputs {
+		if _, exists := oldOutputs[output.Name]; !exists {
+			report.add(OutputAdded, "output."+output.Name, false, "output added")
+		}
+	}
+
+	return report
+}
+
+// moduleSchema converts a module's variables, treating a module without
+// variables as an empty schema
+func moduleSchema(result *parser.ParseResult) *converter.JSONSchema7 {
+	schema, err := converter.NewConverter().ConvertToJSONSchema7(result)
+	if err != nil {
+		return &converter.JSONSchema7{Properties: map[string]converter.Property{}}
+	}
+	return schema
+}
+
+// CompareSchemas compares the variables described by two generated schemas.
+// Outputs are not part of a schema, so only CompareModules reports them
+func CompareSchemas(oldSchema, newSchema *converter.JSONSchema7) *Report {
+	report := &Report{Changes: []Change{}}
+
+	oldRequired := stringSet(oldSchema.Required)
+	newRequired := stringSet(newSchema.Required)
+
+	for _, name := range propertyNames(oldSchema.Properties, newSchema.Properties) {
+		path := "var." + name
+		old, inOld := oldSchema.Properties[name]
+		updated, inNew := newSchema.Properties[name]
+
+		switch {
+		case !inNew:
+			report.add(VariableRemoved, path, true, "variable removed")
+			continue
+		case !inOld && newRequired[name]:
+			report.add(VariableAdded, path, true, "required variable added")
+			continue
+		case !inOld:
+			report.add(VariableAdded, path, false, "optional variable added")
+			continue
+		}
+
+		switch {
+		case !oldRequired[name] && newRequired[name]:
+			report.add(DefaultRemoved, path, true, "default removed, the variable is now required")
+		case oldRequired[name] && !newRequired[name]:
+			report.add(DefaultAdded, path, false, "default added, the variable is now optional")
+		}
+
+		switch {
+		case !old.WriteOnly && updated.WriteOnly:
+			report.add(SensitiveChanged, path, true, "variable is now sensitive")
+		case old.WriteOnly && !updated.WriteOnly:
+			report.add(SensitiveChanged, path, false, "variable is no longer sensitive")
+		}
+
+		report.compareProperty(path, old, updated)
+	}
+
+	return report
+}
+
+// compareProperty compares the type, constraints and default of a variable
+// or nested attribute, recursing into element and attribute types
+func (r *Report) compareProperty(path string, old, updated converter.Property) {
+	if old.Description != updated.Description {
+		r.add(DescriptionChanged, path, false, "description changed")
+	}
+
+	if old.Default != nil && updated.Default != nil && !equalJSON(old.Default, updated.Default) {
+		r.add(DefaultChanged, path, false, fmt.Sprintf("default changed from %s to %s", jsonString(old.Default), jsonString(updated.Default)))
+	}
+
+	oldTypes, newTypes := typeSet(old.Type), typeSet(updated.Type)
+	if removed := difference(oldTypes, newTypes); len(removed) > 0 {
+		r.add(TypeNarrowed, path, true, fmt.Sprintf("no longer accepts %s", strings.Join(removed, ", ")))
+	}
+	if added := difference(newTypes, oldTypes); len(added) > 0 {
+		r.add(TypeWidened, path, false, fmt.Sprintf("now also accepts %s", strings.Join(added, ", ")))
+	}
+
+	if !old.UniqueItems && updated.UniqueItems {
+		r.add(TypeNarrowed, path, true, "elements must now be unique")
+	} else if old.UniqueItems && !updated.UniqueItems {
+		r.add(TypeWidened, path, false, "elements no longer need to be unique")
+	}
+
+	// Maps and objects are both JSON objects, so a change between them
+	// goes unnoticed by the types above
+	oldShape, newShape := objectShape(old), objectShape(updated)
+	switch {
+	case oldShape == newShape:
+	case newShape == "":
+		r.add(TypeWidened, path, false, fmt.Sprintf("no longer needs to be %s", oldShape))
+	case oldShape == "":
+		r.add(TypeNarrowed, path, true, fmt.Sprintf("must now be %s", newShape))
+	default:
+		r.add(TypeNarrowed, path, true, fmt.Sprintf("changed from %s to %s", oldShape, newShape))
+	}
+
+	r.compareEnum(path, old.Enum, updated.Enum)
+	r.comparePatterns(path, patterns(old), patterns(updated))
+	r.compareAllOf(path, old.AllOf, updated.AllOf)
+
+	r.compareLowerBound(path, "minimum", bound(old.Minimum, old.ExclusiveMinimum), bound(updated.Minimum, updated.ExclusiveMinimum))
+	r.compareUpperBound(path, "maximum", bound(old.Maximum, old.ExclusiveMaximum), bound(updated.Maximum, updated.ExclusiveMaximum))
+	r.compareLowerBound(path, "minimum length", intBound(old.MinLength), intBound(updated.MinLength))
+	r.compareUpperBound(path, "maximum length", intBound(old.MaxLength), intBound(updated.MaxLength))
+	r.compareLowerBound(path, "minimum items", intBound(old.MinItems), intBound(updated.MinItems))
+	r.compareUpperBound(path, "maximum items", intBound(old.MaxItems), intBound(updated.MaxItems))
+	r.compareLowerBound(path, "minimum entries", intBound(old.MinProperties), intBound(updated.MinProperties))
+	r.compareUpperBound(path, "maximum entries", intBound(old.MaxProperties), intBound(updated.MaxProperties))
+
+	// Element types of lists and sets, and value types of maps
+	if oldItems, ok := old.Items.(*converter.Property); ok {
+		if newItems, ok := updated.Items.(*converter.Property); ok {
+			r.compareProperty(path+"[]", *oldItems, *newItems)
+		}
+	}
+	oldTuple, oldIsTuple := old.Items.([]converter.Property)
+	newTuple, newIsTuple := updated.Items.([]converter.Property)
+	if oldIsTuple && newIsTuple {
+		if len(oldTuple) != len(newTuple) {
+			r.add(TypeNarrowed, path, true, fmt.Sprintf("tuple now has %d elements instead of %d", len(newTuple), len(oldTuple)))
+		} else {
+			for i := range oldTuple {
+				r.compareProperty(fmt.Sprintf("%s[%d]", path, i), oldTuple[i], newTuple[i])
+			}
+		}
+	}
+	if oldValues, ok := old.AdditionalProperties.(*converter.Property); ok {
+		if newValues, ok := updated.AdditionalProperties.(*converter.Property); ok {
+			r.compareProperty(path+"{}", *oldValues, *newValues)
+		}
+	}
+
+	// Object attributes
+	if old.Properties != nil && updated.Properties != nil {
+		oldRequired := stringSet(old.Required)
+		newRequired := stringSet(updated.Required)
+		for _, name := range propertyNames(old.Properties, updated.Properties) {
+			attrPath := path + "." + name
+			oldAttr, inOld := old.Properties[name]
+			newAttr, inNew := updated.Properties[name]
+			switch {
+			case !inNew:
+				r.add(AttributeRemoved, attrPath, true, "attribute removed")
+			case !inOld && newRequired[name]:
+				r.add(AttributeAdded, attrPath, true, "required attribute added")
+			case !inOld:
+				r.add(AttributeAdded, attrPath, false, "optional attribute added")
+			default:
+				if !oldRequired[name] && newRequired[name] {
+					r.add(DefaultRemoved, attrPath, true, "attribute is no longer optional")
+				} else if oldRequired[name] && !newRequired[name] {
+					r.add(DefaultAdded, attrPath, false, "attribute is now optional")
+				}
+				r.compareProperty(attrPath, oldAttr, newAttr)
+			}
+		}
+	}
+}
+
+// compareEnum reports allowed values that were removed or added. A new
+// enum on a property that had none restricts every other value
+func (r *Report) compareEnum(path string, old, updated []interface{}) {
+	switch {
+	case old == nil && updated == nil:
+		return
+	case old == nil:
+		r.add(EnumValueRemoved, path, true, fmt.Sprintf("now restricted to %s", jsonString(updated)))
+		return
+	case updated == nil:
+		r.add(EnumValueAdded, path, false, "no longer restricted to a set of values")
+		return
+	}
+
+	var removed, added []interface{}
+	for _, v := range old {
+		if !containsJSON(updated, v) {
+			removed = append(removed, v)
+		}
+	}
+	for _, v := range updated {
+		if !containsJSON(old, v) {
+			added = append(added, v)
+		}
+	}
+	if len(removed) > 0 {
+		r.add(EnumValueRemoved, path, true, fmt.Sprintf("allowed values removed: %s", jsonString(removed)))
+	}
+	if len(added) > 0 {
+		r.add(EnumValueAdded, path, false, fmt.Sprintf("allowed values added: %s", jsonString(added)))
+	}
+}
+
+// comparePatterns reports pattern changes. A changed pattern may reject
+// values the old one accepted, so any change other than removal is breaking
+func (r *Report) comparePatterns(path string, old, updated []string) {
+	removed, added := difference(old, updated), difference(updated, old)
+	if len(removed) == 1 && len(added) == 1 {
+		r.add(PatternChanged, path, true, fmt.Sprintf("pattern changed from %q to %q", removed[0], added[0]))
+		return
+	}
+	for _, pattern := range removed {
+		r.add(PatternChanged, path, false, fmt.Sprintf("pattern %q removed", pattern))
+	}
+	for _, pattern := range added {
+		r.add(PatternChanged, path, true, fmt.Sprintf("must now match %q", pattern))
+	}
+}
+
+// patterns returns the patterns a property must match, which come from
+// several validation rules when the extra ones are held in allOf
+func patterns(property converter.Property) []string {
+	var patterns []string
+	if property.Pattern != "" {
+		patterns = append(patterns, property.Pattern)
+	}
+	for _, constraint := range property.AllOf {
+		if constraint.Pattern != "" {
+			patterns = append(patterns, constraint.Pattern)
+		}
+	}
+	return patterns
+}
+
+// compareAllOf reports the constraints in allOf other than patterns that
+// were added (breaking) or removed
+func (r *Report) compareAllOf(path string, old, updated []converter.Property) {
+	for _, constraint := range old {
+		if !containsConstraint(updated, constraint) {
+			r.add(BoundsRelaxed, path, false, fmt.Sprintf("constraint %s removed", jsonString(constraint)))
+		}
+	}
+	for _, constraint := range updated {
+		if !containsConstraint(old, constraint) {
+			r.add(BoundsTightened, path, true, fmt.Sprintf("constraint %s added", jsonString(constraint)))
+		}
+	}
+}
+
+// containsConstraint reports whether constraints holds one with the same
+// JSON encoding as constraint, treating pattern-only constraints as present
+// since comparePatterns covers them
+func containsConstraint(constraints []converter.Property, constraint converter.Property) bool {
+	if constraint.Pattern != "" && equalJSON(converter.Property{Pattern: constraint.Pattern}, constraint) {
+		return true
+	}
+	for _, candidate := range constraints {
+		if equalJSON(candidate, constraint) {
+			return true
+		}
+	}
+	return false
+}
+
+// objectShape describes what an object property must look like: a map
+// whose values share a schema, an object with attributes, or neither
+func objectShape(property converter.Property) string {
+	switch {
+	case property.AdditionalProperties != nil:
+		return "a map"
+	case len(property.Properties) > 0:
+		return "an object with attributes"
+	}
+	return ""
+}
+
+// limit is a numeric bound, which is exclusive for exclusiveMinimum and
+// exclusiveMaximum
+type limit struct {
+	value     float64
+	exclusive bool
+}
+
+func (l limit) String() string {
+	if l.exclusive {
+		return fmt.Sprintf("%g (exclusive)", l.value)
+	}
+	return fmt.Sprintf("%g", l.value)
+}
+
+// bound returns the effective limit of an inclusive and an exclusive bound
+func bound(inclusive, exclusive *float64) *limit {
+	switch {
+	case exclusive != nil:
+		return &limit{value: *exclusive, exclusive: true}
+	case inclusive != nil:
+		return &limit{value: *inclusive}
+	}
+	return nil
+}
+
+func intBound(v *int) *limit {
+	if v == nil {
+		return nil
+	}
+	return &limit{value: float64(*v)}
+}
+
+// compareLowerBound reports a raised (breaking) or lowered lower bound
+func (r *Report) compareLowerBound(path, name string, old, updated *limit) {
+	switch {
+	case old == nil && updated == nil:
+	case old == nil:
+		r.add(BoundsTightened, path, true, fmt.Sprintf("%s %s added", name, updated))
+	case updated == nil:
+		r.add(BoundsRelaxed, path, false, fmt.Sprintf("%s %s removed", name, old))
+	case updated.value > old.value || (updated.value == old.value && updated.exclusive && !old.exclusive):
+		r.add(BoundsTightened, path, true, fmt.Sprintf("%s raised from %s to %s", name, old, updated))
+	case *updated != *old:
+		r.add(BoundsRelaxed, path, false, fmt.Sprintf("%s lowered from %s to %s", name, old, updated))
+	}
+}
+
+// compareUpperBound reports a lowered (breaking) or raised upper bound
+func (r *Report) compareUpperBound(path, name string, old, updated *limit) {
+	switch {
+	case old == nil && updated == nil:
+	case old == nil:
+		r.add(BoundsTightened, path, true, fmt.Sprintf("%s %s added", name, updated))
+	case updated == nil:
+		r.add(BoundsRelaxed, path, false, fmt.Sprintf("%s %s removed", name, old))
+	case updated.value < old.value || (updated.value == old.value && updated.exclusive && !old.exclusive):
+		r.add(BoundsTightened, path, true, fmt.Sprintf("%s lowered from %s to %s", name, old, updated))
+	case *updated != *old:
+		r.add(BoundsRelaxed, path, false, fmt.Sprintf("%s raised from %s to %s", name, old, updated))
+	}
+}
+
+func (r *Report) add(kind ChangeKind, path string, breaking bool, message string) {
+	r.Changes = append(r.Changes, Change{Kind: kind, Path: path, Breaking: breaking, Message: message})
+}
+
+// propertyNames returns the names of the old properties in declaration
+// order, followed by those only in the new properties
+func propertyNames(old, updated map[string]converter.Property) []string {
+	names := orderedNames(old)
+	for _, name := range orderedNames(updated) {
+		if _, exists := old[name]; !exists {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// orderedNames sorts property names by x-order, then by name
+func orderedNames(properties map[string]converter.Property) []string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		oi, oj := properties[names[i]].Order, properties[names[j]].Order
+		if oi != oj {
+			return oi < oj
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// typeSet returns the JSON types a property accepts. A schema read from a
+// file holds []interface{} where a converted one holds []string
+func typeSet(t interface{}) []string {
+	switch t := t.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// difference returns the elements of a that are not in b
+func difference(a, b []string) []string {
+	in := stringSet(b)
+	var diff []string
+	for _, s := range a {
+		if !in[s] {
+			diff = append(diff, s)
+		}
+	}
+	return diff
+}
+
+func stringSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// equalJSON compares values by their JSON encoding, so that values decoded
+// from a file compare equal to converted ones
+func equalJSON(a, b interface{}) bool {
+	return jsonString(a) == jsonString(b) || reflect.DeepEqual(a, b)
+}
+
+func containsJSON(values []interface{}, v interface{}) bool {
+	for _, candidate := range values {
+		if equalJSON(candidate, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonString(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,377 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/samart/terraform-schema-generator/pkg/converter"
+	"github.com/samart/terraform-schema-generator/pkg/parser"
+)
+
+// parseModule parses a single-file module
+func parseModule(t *testing.T, content string) *parser.ParseResult {
+	t.Helper()
+
+	result, err := parser.NewParser().ParseFiles(map[string]io.Reader{
+		"main.tf": strings.NewReader(content),
+	})
+	require.NoError(t, err)
+	return result
+}
+
+// changesByPath indexes changes as "path kind" for compact assertions
+func changesByPath(report *Report) map[string]Change {
+	changes := make(map[string]Change, len(report.Changes))
+	for _, change := range report.Changes {
+		changes[change.Path+" "+string(change.Kind)] = change
+	}
+	return changes
+}
+
+const oldModule = `
+variable "name" {
+  type = string
+}
+
+variable "environment" {
+  type    = string
+  default = "dev"
+  validation {
+    condition     = contains(["dev", "staging", "prod"], var.environment)
+    error_message = "Unknown environment."
+  }
+}
+
+variable "replicas" {
+  type    = number
+  default = 1
+  validation {
+    condition     = var.replicas >= 1 && var.replicas <= 10
+    error_message = "Between 1 and 10."
+  }
+}
+
+variable "ports" {
+  type = list(object({
+    number   = number
+    protocol = optional(string, "tcp")
+  }))
+  default = []
+}
+
+variable "password" {
+  type    = string
+  default = ""
+}
+
+variable "legacy" {
+  type    = any
+  default = null
+}
+
+output "id" {
+  value = "x"
+}
+
+output "arn" {
+  value = "y"
+}
+`
+
+const newModule = `
+variable "name" {
+  type        = string
+  description = "Name of the service"
+}
+
+variable "environment" {
+  type    = string
+  default = "dev"
+  validation {
+    condition     = contains(["dev", "prod", "test"], var.environment)
+    error_message = "Unknown environment."
+  }
+}
+
+variable "replicas" {
+  type = number
+  validation {
+    condition     = var.replicas >= 2 && var.replicas <= 20
+    error_message = "Between 2 and 20."
+  }
+}
+
+variable "ports" {
+  type = list(object({
+    number   = number
+    protocol = string
+  }))
+  default = []
+}
+
+variable "password" {
+  type      = string
+  default   = ""
+  sensitive = true
+}
+
+variable "region" {
+  type = string
+}
+
+variable "tags" {
+  type    = map(string)
+  default = {}
+}
+
+output "id" {
+  value     = "x"
+  sensitive = true
+}
+
+output "url" {
+  value = "z"
+}
+`
+
+func TestCompareModules(t *testing.T) {
+	report := CompareModules(parseModule(t, oldModule), parseModule(t, newModule))
+	changes := changesByPath(report)
+
+	breaking := []string{
+		"var.legacy variable_removed",
+		"var.region variable_added",
+		"var.environment enum_value_removed",
+		"var.replicas default_removed",
+		"var.replicas bounds_tightened",
+		"var.ports[].protocol default_removed",
+		"var.password sensitive_changed",
+		"output.id sensitive_changed",
+		"output.arn output_removed",
+	}
+	for _, key := range breaking {
+		change, ok := changes[key]
+		if assert.True(t, ok, "expected change %s", key) {
+			assert.True(t, change.Breaking, "%s should be breaking", key)
+		}
+	}
+
+	nonBreaking := []string{
+		"var.name description_changed",
+		"var.environment enum_value_added",
+		"var.tags variable_added",
+		"output.url output_added",
+	}
+	for _, key := range nonBreaking {
+		change, ok := changes[key]
+		if assert.True(t, ok, "expected change %s", key) {
+			assert.False(t, change.Breaking, "%s should not be breaking", key)
+		}
+	}
+
+	assert.Contains(t, changes["var.environment enum_value_removed"].Message, `"staging"`)
+	assert.Contains(t, changes["var.replicas bounds_tightened"].Message, "minimum raised from 1 to 2")
+	assert.True(t, report.HasBreaking())
+	assert.Equal(t, BumpMajor, report.SuggestedBump())
+}
+
+func TestCompareModules_TypeChanges(t *testing.T) {
+	old := parseModule(t, `
+variable "a" {
+  type = string
+}
+variable "b" {
+  type = list(string)
+}
+variable "c" {
+  type     = string
+  nullable = false
+}
+`)
+	updated := parseModule(t, `
+variable "a" {
+  type = any
+}
+variable "b" {
+  type = set(string)
+}
+variable "c" {
+  type = number
+}
+`)
+	changes := changesByPath(CompareModules(old, updated))
+
+	assert.False(t, changes["var.a type_widened"].Breaking)
+	assert.NotContains(t, changes, "var.a type_narrowed")
+	assert.True(t, changes["var.b type_narrowed"].Breaking)
+	assert.Equal(t, "elements must now be unique", changes["var.b type_narrowed"].Message)
+	assert.Equal(t, "no longer accepts string", changes["var.c type_narrowed"].Message)
+	assert.Equal(t, "now also accepts number, null", changes["var.c type_widened"].Message)
+}
+
+func TestCompareModules_MapsAndObjects(t *testing.T) {
+	old := parseModule(t, `
+variable "tags" {
+  type = map(string)
+}
+variable "settings" {
+  type = object({ name = string })
+}
+variable "labels" {
+  type = map(string)
+}
+`)
+	updated := parseModule(t, `
+variable "tags" {
+  type = object({ team = optional(string) })
+}
+variable "settings" {
+  type = map(string)
+}
+variable "labels" {
+  type = any
+}
+`)
+	changes := changesByPath(CompareModules(old, updated))
+
+	assert.True(t, changes["var.tags type_narrowed"].Breaking)
+	assert.Equal(t, "changed from a map to an object with attributes", changes["var.tags type_narrowed"].Message)
+	assert.True(t, changes["var.settings type_narrowed"].Breaking)
+	assert.Equal(t, "changed from an object with attributes to a map", changes["var.settings type_narrowed"].Message)
+	assert.NotContains(t, changes, "var.labels type_narrowed")
+	assert.Equal(t, "no longer needs to be a map", changes["var.labels type_widened"].Message)
+}
+
+func TestCompareModules_PatternRules(t *testing.T) {
+	rules := func(patterns ...string) string {
+		var b strings.Builder
+		b.WriteString("variable \"name\" {\n  type = string\n")
+		for _, pattern := range patterns {
+			fmt.Fprintf(&b, "  validation {\n    condition     = can(regex(%q, var.name))\n    error_message = \"Invalid.\"\n  }\n", pattern)
+		}
+		b.WriteString("}\n")
+		return b.String()
+	}
+
+	t.Run("rule added", func(t *testing.T) {
+		report := CompareModules(parseModule(t, rules("^[a-z]+$")), parseModule(t, rules("^[a-z]+$", "^[0-9a-z]{3,}$")))
+		require.Len(t, report.Changes, 1)
+		assert.Equal(t, PatternChanged, report.Changes[0].Kind)
+		assert.True(t, report.Changes[0].Breaking)
+		assert.Equal(t, `must now match "^[0-9a-z]{3,}$"`, report.Changes[0].Message)
+	})
+
+	t.Run("first rule removed", func(t *testing.T) {
+		report := CompareModules(parseModule(t, rules("^[a-z]+$", "^[0-9a-z]{3,}$")), parseModule(t, rules("^[0-9a-z]{3,}$")))
+		require.Len(t, report.Changes, 1)
+		assert.False(t, report.Changes[0].Breaking)
+		assert.Equal(t, `pattern "^[a-z]+$" removed`, report.Changes[0].Message)
+	})
+
+	t.Run("second rule changed", func(t *testing.T) {
+		report := CompareModules(parseModule(t, rules("^[a-z]+$", "^[0-9a-z]{3,}$")), parseModule(t, rules("^[a-z]+$", "^[0-9a-z]{5,}$")))
+		require.Len(t, report.Changes, 1)
+		assert.True(t, report.Changes[0].Breaking)
+		assert.Equal(t, `pattern changed from "^[0-9a-z]{3,}$" to "^[0-9a-z]{5,}$"`, report.Changes[0].Message)
+	})
+}
+
+func TestSuggestedBump(t *testing.T) {
+	base := `
+variable "name" {
+  type = string
+}
+`
+	tests := []struct {
+		name    string
+		updated string
+		want    Bump
+	}{
+		{"identical", base, BumpNone},
+		{"description only", `
+variable "name" {
+  type        = string
+  description = "The name"
+}
+`, BumpPatch},
+		{"optional variable added", base + `
+variable "tags" {
+  type    = map(string)
+  default = {}
+}
+`, BumpMinor},
+		{"required variable added", base + `
+variable "region" {
+  type = string
+}
+`, BumpMajor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := CompareModules(parseModule(t, base), parseModule(t, tt.updated))
+			assert.Equal(t, tt.want, report.SuggestedBump())
+		})
+	}
+}
+
+func TestCompareSchemas_FromJSON(t *testing.T) {
+	toSchema := func(content string) *converter.JSONSchema7 {
+		c := converter.NewConverter()
+		schema, err := c.ConvertToJSONSchema7(parseModule(t, content))
+		require.NoError(t, err)
+		out, err := c.ToJSON(schema)
+		require.NoError(t, err)
+		parsed, err := converter.ParseJSONSchema7(out)
+		require.NoError(t, err)
+		return parsed
+	}
+
+	old := toSchema(oldModule)
+	report := CompareSchemas(old, toSchema(newModule))
+	changes := changesByPath(report)
+
+	assert.Contains(t, changes, "var.ports[].protocol default_removed")
+	assert.Contains(t, changes, "var.replicas bounds_tightened")
+	assert.NotContains(t, changes, "output.arn output_removed", "outputs are not part of schemas")
+
+	assert.Empty(t, CompareSchemas(old, toSchema(oldModule)).Changes)
+}
+
+func TestCompareDirectories(t *testing.T) {
+	oldDir, newDir := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(oldDir, "main.tf"), []byte(oldModule), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(newDir, "main.tf"), []byte(newModule), 0644))
+
+	report, err := CompareDirectories(oldDir, newDir)
+	require.NoError(t, err)
+	assert.Equal(t, BumpMajor, report.SuggestedBump())
+
+	_, err = CompareDirectories(oldDir, filepath.Join(newDir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestWriteReport(t *testing.T) {
+	var buf bytes.Buffer
+	report := CompareModules(parseModule(t, oldModule), parseModule(t, newModule))
+	require.NoError(t, WriteReport(&buf, report))
+
+	out := buf.String()
+	assert.Contains(t, out, "Breaking changes:\n")
+	assert.Contains(t, out, "  ✗ var.legacy: variable removed\n")
+	assert.Contains(t, out, "Non-breaking changes:\n")
+	assert.Contains(t, out, "  • output.url: output added\n")
+	assert.True(t, strings.HasSuffix(out, "Suggested version bump: major\n"))
+
+	buf.Reset()
+	require.NoError(t, WriteReport(&buf, &Report{}))
+	assert.Equal(t, "No changes.\n\nSuggested version bump: none\n", buf.String())
+}
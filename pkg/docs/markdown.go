@@ -0,0 +1,270 @@
+package docs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/samart/terraform-schema-generator/pkg/converter"
+	"github.com/samart/terraform-schema-generator/pkg/parser"
+)
+
+// Markers delimit the generated section of a README. They are the markers
+// terraform-docs uses, so READMEs already set up for it work unchanged
+const (
+	BeginMarker = "<!-- BEGIN_TF_DOCS -->"
+	EndMarker   = "<!-- END_TF_DOCS -->"
+)
+
+// Markdown renders the requirements, providers, modules, resources, inputs
+// and outputs of a module as Markdown tables. The schema supplies the
+// constraints derived from validation rules; it may be nil, in which case
+// only the validation error messages are listed
+func Markdown(result *parser.ParseResult, schema *converter.JSONSchema7) []byte {
+	var buf bytes.Buffer
+
+	writeRequirements(&buf, result)
+	writeModules(&buf, result.Modules)
+	writeResources(&buf, result.Resources, result.DataSources)
+	writeInputs(&buf, result.Variables, schema)
+	writeOutputs(&buf, result.Outputs)
+
+	return bytes.TrimRight(buf.Bytes(), "\n")
+}
+
+// Inject replaces the content between BeginMarker and EndMarker in readme
+// with content, keeping the markers and everything around them
+func Inject(readme, content []byte) ([]byte, error) {
+	begin := bytes.Index(readme, []byte(BeginMarker))
+	if begin < 0 {
+		return nil, fmt.Errorf("marker %s not found", BeginMarker)
+	}
+	start := begin + len(BeginMarker)
+	end := bytes.Index(readme[start:], []byte(EndMarker))
+	if end < 0 {
+		return nil, fmt.Errorf("marker %s not found after %s", EndMarker, BeginMarker)
+	}
+	end += start
+
+	var buf bytes.Buffer
+	buf.Write(readme[:start])
+	buf.WriteString("\n")
+	buf.Write(content)
+	buf.WriteString("\n")
+	buf.Write(readme[end:])
+	return buf.Bytes(), nil
+}
+
+func writeRequirements(buf *bytes.Buffer, result *parser.ParseResult) {
+	if result.TerraformVersion == "" && len(result.Providers) == 0 {
+		return
+	}
+
+	buf.WriteString("## Requirements\n\n")
+	buf.WriteString("| Name | Source | Version |\n")
+	buf.WriteString("|------|--------|---------|\n")
+	if result.TerraformVersion != "" {
+		fmt.Fprintf(buf, "| terraform | n/a | %s |\n", code(result.TerraformVersion))
+	}
+	for _, provider := range result.Providers {
+		fmt.Fprintf(buf, "| %s | %s | %s |\n", cell(provider.Name), orNA(provider.Source), orNA(provider.Version))
+	}
+	buf.WriteString("\n")
+}
+
+func writeModules(buf *bytes.Buffer, modules []parser.Module) {
+	if len(modules) == 0 {
+		return
+	}
+
+	buf.WriteString("## Modules\n\n")
+	buf.WriteString("| Name | Source | Version |\n")
+	buf.WriteString("|------|--------|---------|\n")
+	for _, module := range modules {
+		fmt.Fprintf(buf, "| %s | %s | %s |\n", cell(module.Name), orNA(module.Source), orNA(module.Version))
+	}
+	buf.WriteString("\n")
+}
+
+// writeResources lists the managed resources, then the data sources
+func writeResources(buf *bytes.Buffer, resources, dataSources []parser.Resource) {
+	if len(resources) == 0 && len(dataSources) == 0 {
+		return
+	}
+
+	buf.WriteString("## Resources\n\n")
+	buf.WriteString("| Name | Type |\n")
+	buf.WriteString("|------|------|\n")
+	for _, resource := range resources {
+		fmt.Fprintf(buf, "| %s | resource |\n", code(resource.Type+"."+resource.Name))
+	}
+	for _, dataSource := range dataSources {
+		fmt.Fprintf(buf, "| %s | data |\n", code("data."+dataSource.Type+"."+dataSource.Name))
+	}
+	buf.WriteString("\n")
+}
+
+func writeInputs(buf *bytes.Buffer, variables []parser.Variable, schema *converter.JSONSchema7) {
+	if len(variables) == 0 {
+		return
+	}
+
+	buf.WriteString("## Inputs\n\n")
+	buf.WriteString("| Name | Description | Type | Default | Required | Sensitive | Constraints |\n")
+	buf.WriteString("|------|-------------|------|---------|:--------:|:---------:|-------------|\n")
+	for _, variable := range variables {
+		var property *converter.Property
+		if schema != nil {
+			if p, ok := schema.Properties[variable.Name]; ok {
+				property = &p
+			}
+		}
+
+		fmt.Fprintf(buf, "| %s | %s | %s | %s | %s | %s | %s |\n",
+			code(variable.Name),
+			cell(variable.Description),
+			code(typeString(variable)),
+			defaultString(variable),
+			yesNo(variable.Required),
+			yesNo(variable.Sensitive),
+			strings.Join(constraints(variable, property), "<br>"),
+		)
+	}
+	buf.WriteString("\n")
+}
+
+func writeOutputs(buf *bytes.Buffer, outputs []parser.Output) {
+	if len(outputs) == 0 {
+		return
+	}
+
+	buf.WriteString("## Outputs\n\n")
+	buf.WriteString("| Name | Description | Sensitive |\n")
+	buf.WriteString("|------|-------------|:---------:|\n")
+	for _, output := range outputs {
+		fmt.Fprintf(buf, "| %s | %s | %s |\n", code(output.Name), cell(output.Description), yesNo(output.Sensitive))
+	}
+	buf.WriteString("\n")
+}
+
+// typeString returns the canonical form of a variable's type constraint
+func typeString(variable parser.Variable) string {
+	if variable.TypeSpec != nil {
+		return variable.TypeSpec.String()
+	}
+	if variable.Type != "" {
+		return strings.Join(strings.Fields(variable.Type), " ")
+	}
+	return string(parser.TypeAny)
+}
+
+// defaultString renders a variable's default as JSON, or n/a when required
+func defaultString(variable parser.Variable) string {
+	if variable.Required {
+		return "n/a"
+	}
+	data, err := json.Marshal(variable.Default)
+	if err != nil {
+		return "n/a"
+	}
+	return code(string(data))
+}
+
+// constraints describes the JSON Schema keywords derived from validation
+// rules, followed by the error messages of the rules
+func constraints(variable parser.Variable, property *converter.Property) []string {
+	var lines []string
+	if property != nil {
+		if len(property.Enum) > 0 {
+			values := make([]string, len(property.Enum))
+			for i, v := range property.Enum {
+				data, _ := json.Marshal(v)
+				values[i] = code(string(data))
+			}
+			lines = append(lines, "one of "+strings.Join(values, ", "))
+		}
+		if property.Pattern != "" {
+			lines = append(lines, "matches "+code(property.Pattern))
+		}
+		for _, allOf := range property.AllOf {
+			if allOf.Pattern != "" {
+				lines = append(lines, "matches "+code(allOf.Pattern))
+			}
+		}
+		lines = appendRange(lines, "value", property.Minimum, property.ExclusiveMinimum, property.Maximum, property.ExclusiveMaximum)
+		lines = appendIntRange(lines, "length", property.MinLength, property.MaxLength)
+		lines = appendIntRange(lines, "items", property.MinItems, property.MaxItems)
+		lines = appendIntRange(lines, "entries", property.MinProperties, property.MaxProperties)
+	}
+
+	for _, rule := range variable.Validations {
+		if rule.ErrorMessage != "" {
+			lines = append(lines, cell(rule.ErrorMessage))
+		}
+	}
+	return lines
+}
+
+// appendRange describes numeric bounds such as 1 ≤ value < 10
+func appendRange(lines []string, name string, minimum, exclusiveMinimum, maximum, exclusiveMaximum *float64) []string {
+	var parts []string
+	switch {
+	case exclusiveMinimum != nil:
+		parts = append(parts, fmt.Sprintf("%g <", *exclusiveMinimum))
+	case minimum != nil:
+		parts = append(parts, fmt.Sprintf("%g ≤", *minimum))
+	}
+	parts = append(parts, name)
+	switch {
+	case exclusiveMaximum != nil:
+		parts = append(parts, fmt.Sprintf("< %g", *exclusiveMaximum))
+	case maximum != nil:
+		parts = append(parts, fmt.Sprintf("≤ %g", *maximum))
+	}
+	if len(parts) == 1 {
+		return lines
+	}
+	return append(lines, strings.Join(parts, " "))
+}
+
+// appendIntRange describes length or size bounds
+func appendIntRange(lines []string, name string, minimum, maximum *int) []string {
+	var lower, upper *float64
+	if minimum != nil {
+		v := float64(*minimum)
+		lower = &v
+	}
+	if maximum != nil {
+		v := float64(*maximum)
+		upper = &v
+	}
+	return appendRange(lines, name, lower, nil, upper, nil)
+}
+
+// cell escapes text for use in a table cell
+func cell(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// code formats text as inline code within a table cell
+func code(s string) string {
+	return "`" + cell(s) + "`"
+}
+
+func orNA(s string) string {
+	if s == "" {
+		return "n/a"
+	}
+	return code(s)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
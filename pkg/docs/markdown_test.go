@@ -0,0 +1,157 @@
+package docs
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/samart/terraform-schema-generator/pkg/converter"
+	"github.com/samart/terraform-schema-generator/pkg/parser"
+)
+
+const docsTestConfig = `
+terraform {
+  required_version = ">= 1.5"
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+  }
+}
+
+variable "name" {
+  type        = string
+  description = "Name of the bucket | used as a prefix"
+  validation {
+    condition     = can(regex("^[a-z-]+$", var.name)) && length(var.name) <= 63
+    error_message = "Lowercase letters and dashes, at most 63 characters."
+  }
+}
+
+variable "environment" {
+  type    = string
+  default = "dev"
+  validation {
+    condition     = contains(["dev", "prod"], var.environment)
+    error_message = "Unknown environment."
+  }
+}
+
+variable "replicas" {
+  type    = number
+  default = 2
+  validation {
+    condition     = var.replicas >= 1 && var.replicas < 10
+    error_message = "Between 1 and 9."
+  }
+}
+
+variable "rules" {
+  type = list(object({
+    port = number
+  }))
+  default = []
+}
+
+variable "password" {
+  type      = string
+  sensitive = true
+}
+
+module "network" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.1.0"
+}
+
+resource "aws_s3_bucket" "this" {
+  bucket = var.name
+}
+
+data "aws_caller_identity" "current" {}
+
+output "bucket_arn" {
+  description = "ARN of the bucket"
+  value       = aws_s3_bucket.this.arn
+}
+
+output "password" {
+  value     = var.password
+  sensitive = true
+}
+`
+
+// renderTestDocs renders docsTestConfig with its schema
+func renderTestDocs(t *testing.T) string {
+	t.Helper()
+
+	result, err := parser.NewParser().ParseFiles(map[string]io.Reader{
+		"main.tf": strings.NewReader(docsTestConfig),
+	})
+	require.NoError(t, err)
+	schema, err := converter.NewConverter().ConvertToJSONSchema7(result)
+	require.NoError(t, err)
+
+	return string(Markdown(result, schema))
+}
+
+func TestMarkdown(t *testing.T) {
+	out := renderTestDocs(t)
+
+	t.Run("requirements, modules and resources", func(t *testing.T) {
+		assert.Contains(t, out, "## Requirements\n\n| Name | Source | Version |\n|------|--------|---------|\n"+
+			"| terraform | n/a | `>= 1.5` |\n"+
+			"| aws | `hashicorp/aws` | `~> 5.0` |\n")
+		assert.Contains(t, out, "| network | `terraform-aws-modules/vpc/aws` | `5.1.0` |\n")
+		assert.Contains(t, out, "| `aws_s3_bucket.this` | resource |\n"+
+			"| `data.aws_caller_identity.current` | data |\n")
+	})
+
+	t.Run("inputs", func(t *testing.T) {
+		assert.Contains(t, out, "| `name` | Name of the bucket \\| used as a prefix | `string` | n/a | yes | no | "+
+			"matches `^[\\-a-z]+$`<br>length ≤ 63<br>Lowercase letters and dashes, at most 63 characters. |\n")
+		assert.Contains(t, out, "| `environment` |  | `string` | `\"dev\"` | no | no | one of `\"dev\"`, `\"prod\"`<br>Unknown environment. |\n")
+		assert.Contains(t, out, "| `replicas` |  | `number` | `2` | no | no | 1 ≤ value < 10<br>Between 1 and 9. |\n")
+		assert.Contains(t, out, "| `rules` |  | `list(object({port = number}))` | `[]` | no | no |  |\n")
+		assert.Contains(t, out, "| `password` |  | `string` | n/a | yes | yes |  |\n")
+	})
+
+	t.Run("outputs", func(t *testing.T) {
+		assert.Contains(t, out, "| `bucket_arn` | ARN of the bucket | no |\n")
+		assert.True(t, strings.HasSuffix(out, "| `password` |  | yes |"))
+	})
+
+	t.Run("without schema", func(t *testing.T) {
+		result, err := parser.NewParser().ParseFiles(map[string]io.Reader{
+			"main.tf": strings.NewReader(docsTestConfig),
+		})
+		require.NoError(t, err)
+		out := string(Markdown(result, nil))
+		assert.Contains(t, out, "| `environment` |  | `string` | `\"dev\"` | no | no | Unknown environment. |\n")
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		assert.Equal(t, out, renderTestDocs(t))
+	})
+}
+
+func TestInject(t *testing.T) {
+	readme := "# Module\n\nIntro.\n\n" + BeginMarker + "\nstale\n" + EndMarker + "\n\nFooter.\n"
+
+	updated, err := Inject([]byte(readme), []byte("## Inputs"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Module\n\nIntro.\n\n"+BeginMarker+"\n## Inputs\n"+EndMarker+"\n\nFooter.\n", string(updated))
+
+	again, err := Inject(updated, []byte("## Inputs"))
+	require.NoError(t, err)
+	assert.Equal(t, updated, again, "injecting the same content is idempotent")
+
+	_, err = Inject([]byte("# Module\n"), []byte("x"))
+	assert.ErrorContains(t, err, BeginMarker)
+
+	_, err = Inject([]byte(BeginMarker+"\n"), []byte("x"))
+	assert.ErrorContains(t, err, EndMarker)
+}
@@ -1,9 +1,12 @@
 package generator
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/samart/terraform-schema-generator/pkg/converter"
 	"github.com/samart/terraform-schema-generator/pkg/parser"
@@ -17,14 +20,70 @@ type Generator struct {
 	schema     *converter.JSONSchema7
 	schemaJSON []byte
 	errors     []error
+
+	converterOptions  []converter.Option
+	metaSchemaOptions []validator.MetaSchemaOption
+	diagnostics       parser.Diagnostics
+	strict            bool
+
+	// dir is the directory of the root module, which local module
+	// sources are relative to
+	dir              string
+	loadModules      bool
+	installedModules bool
+}
+
+// Option configures a Generator
+type Option func(*Generator)
+
+// WithStrict makes Parse fail when any file has a syntax error or a block
+// that cannot be extracted. Without it, parsing is best-effort: problem files
+// are skipped and their diagnostics are only reported through Diagnostics
+func WithStrict(strict bool) Option {
+	return func(g *Generator) {
+		g.strict = strict
+	}
+}
+
+// WithModules makes Parse also load the local modules the configuration
+// calls, recursively, into the Config of each module block. Local sources
+// are resolved against the directory passed to FromDirectory, or that of
+// the first file added
+func WithModules(load bool) Option {
+	return func(g *Generator) {
+		g.loadModules = load
+	}
+}
+
+// WithInstalledModules makes Parse load the modules the configuration calls
+// like WithModules, and also the registry and git modules terraform init
+// downloaded, which are found through the root module's
+// .terraform/modules/modules.json manifest. Nothing is fetched over the
+// network, so modules that were not installed are left unloaded
+func WithInstalledModules(load bool) Option {
+	return func(g *Generator) {
+		g.installedModules = load
+	}
+}
+
+// WithMetaSchemaFile makes ValidateAgainstMetaSchema check the schema
+// against a local meta-schema file instead of the embedded Draft 7 one
+func WithMetaSchemaFile(path string) Option {
+	return func(g *Generator) {
+		g.metaSchemaOptions = append(g.metaSchemaOptions, validator.WithMetaSchemaFile(path))
+	}
 }
 
 // New creates a new Generator instance
-func New() *Generator {
-	return &Generator{
+func New(opts ...Option) *Generator {
+	g := &Generator{
 		files:  make(map[string]io.Reader),
 		errors: []error{},
 	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
 }
 
 // FromFile adds a Terraform file from the filesystem
@@ -35,6 +94,9 @@ func (g *Generator) FromFile(path string) *Generator {
 		return g
 	}
 	g.files[path] = file
+	if g.dir == "" {
+		g.dir = filepath.Dir(path)
+	}
 	return g
 }
 
@@ -50,13 +112,16 @@ func (g *Generator) FromReader(name string, reader io.Reader) *Generator {
 	return g
 }
 
-// FromDirectory adds all .tf files from a directory
+// FromDirectory adds all .tf and .tf.json files from a directory
 func (g *Generator) FromDirectory(path string) *Generator {
 	entries, err := os.ReadDir(path)
 	if err != nil {
 		g.errors = append(g.errors, fmt.Errorf("failed to read directory %s: %w", path, err))
 		return g
 	}
+	if g.dir == "" {
+		g.dir = path
+	}
 
 	for _, entry := range entries {
 		if entry.IsDir() {
@@ -64,7 +129,7 @@ func (g *Generator) FromDirectory(path string) *Generator {
 		}
 
 		name := entry.Name()
-		if len(name) < 3 || name[len(name)-3:] != ".tf" {
+		if !strings.HasSuffix(name, ".tf") && !strings.HasSuffix(name, ".tf.json") {
 			continue
 		}
 
@@ -75,6 +140,13 @@ func (g *Generator) FromDirectory(path string) *Generator {
 	return g
 }
 
+// WithSourceLocations records the source file and lines of each variable in
+// the generated schema as an x-terraform-source extension
+func (g *Generator) WithSourceLocations() *Generator {
+	g.converterOptions = append(g.converterOptions, converter.WithSourceLocations())
+	return g
+}
+
 // Parse parses all added Terraform files
 func (g *Generator) Parse() *Generator {
 	if len(g.errors) > 0 {
@@ -83,12 +155,41 @@ func (g *Generator) Parse() *Generator {
 
 	p := parser.NewParser()
 	result, err := p.ParseFiles(g.files)
-	if err != nil {
+
+	var diags parser.Diagnostics
+	if err != nil && !errors.As(err, &diags) {
 		g.errors = append(g.errors, fmt.Errorf("parse failed: %w", err))
 		return g
 	}
 
+	if g.loadModules || g.installedModules {
+		dir := g.dir
+		if dir == "" {
+			dir = "."
+		}
+
+		var moduleOpts []parser.ModuleOption
+		if g.installedModules {
+			manifest, err := parser.ReadModuleManifest(filepath.Join(dir, parser.DefaultModuleManifest))
+			if err != nil {
+				g.errors = append(g.errors, fmt.Errorf("%w: run \"terraform init\" to install the modules", err))
+				return g
+			}
+			moduleOpts = append(moduleOpts, parser.WithModuleManifest(manifest))
+		}
+		p.LoadModules(result, dir, moduleOpts...)
+	}
+
 	g.result = result
+	g.diagnostics = append(g.diagnostics, result.Diagnostics...)
+
+	// In strict mode every error diagnostic is a failure in its own right
+	if g.strict {
+		for _, diag := range result.Diagnostics.Errors() {
+			g.errors = append(g.errors, fmt.Errorf("parse failed: %w", diag))
+		}
+	}
+
 	return g
 }
 
@@ -103,8 +204,9 @@ func (g *Generator) Convert() *Generator {
 		return g
 	}
 
-	c := converter.NewConverter()
+	c := converter.NewConverter(g.converterOptions...)
 	schema, err := c.ConvertToJSONSchema7(g.result)
+	g.diagnostics = append(g.diagnostics, c.Diagnostics()...)
 	if err != nil {
 		g.errors = append(g.errors, fmt.Errorf("conversion failed: %w", err))
 		return g
@@ -143,7 +245,9 @@ func (g *Generator) Validate() *Generator {
 	return g
 }
 
-// ValidateAgainstMetaSchema validates against JSON Schema Draft 7 meta-schema
+// ValidateAgainstMetaSchema validates against the JSON Schema Draft 7
+// meta-schema, which is embedded so no network access is needed, or the
+// meta-schema given with WithMetaSchemaFile
 func (g *Generator) ValidateAgainstMetaSchema() *Generator {
 	if len(g.errors) > 0 {
 		return g
@@ -154,7 +258,7 @@ func (g *Generator) ValidateAgainstMetaSchema() *Generator {
 		return g
 	}
 
-	v := validator.NewMetaSchemaValidator()
+	v := validator.NewMetaSchemaValidator(g.metaSchemaOptions...)
 	if err := v.ValidateAgainstMetaSchema(g.schemaJSON); err != nil {
 		g.errors = append(g.errors, fmt.Errorf("meta-schema validation failed: %w", err))
 		return g
@@ -229,6 +333,12 @@ func (g *Generator) Error() error {
 	return nil
 }
 
+// Diagnostics returns the warnings and errors reported while processing the
+// Terraform files, including their source locations
+func (g *Generator) Diagnostics() parser.Diagnostics {
+	return g.diagnostics
+}
+
 // Errors returns all errors encountered
 func (g *Generator) Errors() []error {
 	return g.errors
@@ -261,7 +371,7 @@ func FromStringQuick(content string) ([]byte, error) {
 	return New().FromString("terraform.tf", content).Parse().Convert().Validate().JSON()
 }
 
-// FromDirectoryQuick generates a schema from all .tf files in a directory
+// FromDirectoryQuick generates a schema from all .tf and .tf.json files in a directory
 func FromDirectoryQuick(path string) ([]byte, error) {
 	return New().FromDirectory(path).Parse().Convert().Validate().JSON()
 }
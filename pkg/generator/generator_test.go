@@ -2,10 +2,13 @@ package generator
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/samart/terraform-schema-generator/pkg/validator"
 )
 
 const testTerraformConfig = `
@@ -181,6 +184,35 @@ func TestGenerator_FromDirectory(t *testing.T) {
 		assert.Contains(t, string(schemaJSON), `"var1"`)
 	})
 
+	t.Run("includes JSON syntax files", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		err := os.WriteFile(tmpDir+"/variables.tf", []byte(`variable "native" { type = string }`), 0644)
+		require.NoError(t, err)
+
+		err = os.WriteFile(tmpDir+"/generated.tf.json", []byte(`{
+  "variable": {
+    "from_json": { "type": "list(string)", "default": ["a"] }
+  }
+}`), 0644)
+		require.NoError(t, err)
+
+		// Plain JSON files are not Terraform configuration
+		err = os.WriteFile(tmpDir+"/package.json", []byte(`{"name": "x"}`), 0644)
+		require.NoError(t, err)
+
+		schema, err := New(WithStrict(true)).
+			FromDirectory(tmpDir).
+			Parse().
+			Convert().
+			Schema()
+
+		require.NoError(t, err)
+		assert.Len(t, schema.Properties, 2)
+		assert.Contains(t, schema.Properties, "native")
+		assert.Equal(t, []string{"array", "null"}, schema.Properties["from_json"].Type)
+	})
+
 	t.Run("error on nonexistent directory", func(t *testing.T) {
 		err := New().
 			FromDirectory("/nonexistent/directory").
@@ -251,7 +283,7 @@ func TestGenerator_ErrorAccumulation(t *testing.T) {
 	})
 
 	t.Run("errors prevent further execution", func(t *testing.T) {
-		gen := New().
+		gen := New(WithStrict(true)).
 			FromString("test.tf", "invalid").
 			Parse() // This will fail
 
@@ -324,3 +356,237 @@ variable "config" {
 		assert.Contains(t, string(schemaJSON), `"config"`)
 	})
 }
+
+func TestGenerator_WithSourceLocations(t *testing.T) {
+	schema, err := New().
+		WithSourceLocations().
+		FromString("test.tf", testTerraformConfig).
+		Parse().
+		Convert().
+		Schema()
+	require.NoError(t, err)
+
+	source := schema.Properties["optional_var"].Source
+	require.NotNil(t, source)
+	assert.Equal(t, "test.tf", source.Filename)
+	assert.Equal(t, 7, source.Start.Line)
+}
+
+func TestGenerator_Diagnostics(t *testing.T) {
+	const brokenConfig = "variable \"x\" {\n  type = lists(string)\n}\n"
+
+	t.Run("parse errors are exposed as diagnostics", func(t *testing.T) {
+		gen := New().
+			FromString("broken.tf", brokenConfig).
+			Parse()
+
+		diags := gen.Diagnostics()
+		require.Len(t, diags, 1)
+		assert.True(t, diags.HasErrors())
+		assert.Equal(t, "Invalid type specification", diags[0].Summary)
+		require.NotNil(t, diags[0].Subject)
+		assert.Equal(t, "broken.tf", diags[0].Subject.Filename)
+		assert.Equal(t, 2, diags[0].Subject.Start.Line)
+	})
+
+	t.Run("no diagnostics for valid configuration", func(t *testing.T) {
+		gen := New().
+			FromString("test.tf", testTerraformConfig).
+			Parse()
+
+		require.NoError(t, gen.Error())
+		assert.Empty(t, gen.Diagnostics())
+	})
+}
+
+func TestGenerator_StrictMode(t *testing.T) {
+	newGenerator := func(opts ...Option) *Generator {
+		return New(opts...).
+			FromString("a.tf", testTerraformConfig).
+			FromString("b.tf", "variable \"broken\" {\n  type = string\n").
+			FromString("c.tf", "variable \"bad_default\" {\n  type    = number\n  default = \"x\"\n}\n")
+	}
+
+	t.Run("strict mode fails on every error diagnostic", func(t *testing.T) {
+		gen := newGenerator(WithStrict(true)).Parse().Convert()
+
+		errs := gen.Errors()
+		require.Len(t, errs, len(gen.Diagnostics().Errors()))
+		assert.GreaterOrEqual(t, len(errs), 2)
+		assert.Contains(t, errs[0].Error(), "b.tf")
+		assert.Contains(t, errs[len(errs)-1].Error(), "c.tf")
+
+		_, err := gen.Schema()
+		assert.Error(t, err)
+	})
+
+	t.Run("lenient mode keeps what could be parsed", func(t *testing.T) {
+		gen := newGenerator().Parse().Convert()
+		require.NoError(t, gen.Error())
+		assert.True(t, gen.Diagnostics().HasErrors())
+
+		schema, err := gen.Schema()
+		require.NoError(t, err)
+		assert.Contains(t, schema.Properties, "test_var")
+		assert.Contains(t, schema.Properties, "bad_default")
+		assert.NotContains(t, schema.Properties, "broken")
+	})
+
+	t.Run("strict mode passes valid configuration", func(t *testing.T) {
+		_, err := New(WithStrict(true)).
+			FromString("test.tf", testTerraformConfig).
+			Parse().
+			Convert().
+			JSON()
+		assert.NoError(t, err)
+	})
+}
+
+func TestGenerator_WithModules(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "modules", "network"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "main.tf"), []byte(`
+variable "name" {
+  type = string
+}
+
+module "network" {
+  source = "./modules/network"
+}
+
+module "broken" {
+  source = "./modules/missing"
+}
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "modules", "network", "variables.tf"), []byte(`
+variable "cidr" {
+  type = string
+}
+`), 0644))
+
+	t.Run("modules are not loaded by default", func(t *testing.T) {
+		result, err := New(WithStrict(true)).FromDirectory(root).Parse().ParseResult()
+		require.NoError(t, err)
+		assert.Nil(t, result.Modules[0].Config)
+	})
+
+	t.Run("loads local modules", func(t *testing.T) {
+		gen := New(WithModules(true)).FromDirectory(root).Parse()
+		result, err := gen.ParseResult()
+		require.NoError(t, err)
+
+		require.NotNil(t, result.Modules[0].Config)
+		assert.Equal(t, "cidr", result.Modules[0].Config.Variables[0].Name)
+		require.Len(t, gen.Diagnostics(), 1)
+		assert.Equal(t, "Unreadable module directory", gen.Diagnostics()[0].Summary)
+	})
+
+	t.Run("strict mode fails on missing modules", func(t *testing.T) {
+		err := New(WithModules(true), WithStrict(true)).FromDirectory(root).Parse().Error()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Unreadable module directory")
+	})
+}
+
+func TestGenerator_WithInstalledModules(t *testing.T) {
+	root := t.TempDir()
+	installed := filepath.Join(root, ".terraform", "modules", "vpc")
+	require.NoError(t, os.MkdirAll(installed, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "main.tf"), []byte(`
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.0.0"
+}
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(installed, "variables.tf"), []byte(`
+variable "cidr" {
+  type = string
+}
+`), 0644))
+
+	t.Run("fails without a manifest", func(t *testing.T) {
+		err := New(WithInstalledModules(true)).FromDirectory(root).Parse().Error()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "terraform init")
+	})
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".terraform", "modules", "modules.json"), []byte(`{"Modules":[
+  {"Key":"","Source":"","Dir":"."},
+  {"Key":"vpc","Source":"registry.terraform.io/terraform-aws-modules/vpc/aws","Version":"5.0.0","Dir":".terraform/modules/vpc"}
+]}`), 0644))
+
+	t.Run("loads installed modules", func(t *testing.T) {
+		result, err := New(WithInstalledModules(true), WithStrict(true)).FromDirectory(root).Parse().ParseResult()
+		require.NoError(t, err)
+		require.NotNil(t, result.Modules[0].Config)
+		assert.Equal(t, "cidr", result.Modules[0].Config.Variables[0].Name)
+	})
+
+	t.Run("only local modules without the option", func(t *testing.T) {
+		result, err := New(WithModules(true)).FromDirectory(root).Parse().ParseResult()
+		require.NoError(t, err)
+		assert.Nil(t, result.Modules[0].Config)
+	})
+}
+
+func TestGenerator_ConversionWarnings(t *testing.T) {
+	gen := New(WithStrict(true)).
+		FromString("test.tf", `
+variable "name" {
+  type = string
+  validation {
+    condition     = can(regex("(?m)^[a-z]+$", var.name))
+    error_message = "Lowercase only."
+  }
+}
+`).
+		Parse().
+		Convert()
+
+	// Warnings are reported without failing, even in strict mode
+	require.NoError(t, gen.Error())
+	diags := gen.Diagnostics()
+	require.Len(t, diags, 1)
+	assert.Equal(t, "Validation pattern not translated", diags[0].Summary)
+	assert.False(t, diags.HasErrors())
+}
+
+func TestGenerator_WithMetaSchemaFile(t *testing.T) {
+	metaFile := filepath.Join(t.TempDir(), "meta.json")
+	require.NoError(t, os.WriteFile(metaFile, []byte(`{"required": ["x-owner"]}`), 0644))
+
+	err := New(WithMetaSchemaFile(metaFile)).
+		FromString("variables.tf", `variable "name" {}`).
+		Parse().
+		Convert().
+		ValidateAgainstMetaSchema().
+		Error()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "meta-schema validation failed")
+	assert.Contains(t, err.Error(), "x-owner")
+}
+
+func TestGenerator_InputValidation(t *testing.T) {
+	gen := New().
+		FromString("variables.tf", `
+variable "environment" {
+  type = string
+  validation {
+    condition     = contains(["dev", "prod"], var.environment)
+    error_message = "Unknown environment."
+  }
+}
+`).
+		Parse().
+		Convert()
+
+	v, err := validator.NewInputValidatorFromSource(gen)
+	require.NoError(t, err)
+
+	result, err := v.Validate([]byte("environment: staging\n"), validator.FormatYAML)
+	require.NoError(t, err)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "/environment", result.Errors[0].Path)
+	assert.Equal(t, "Unknown environment.", result.Errors[0].ErrorMessage)
+}
@@ -2,6 +2,7 @@ package pkg_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -83,6 +84,40 @@ func validateExampleInputs(t *testing.T, schemaPath, examplesPattern string) int
 	return validCount
 }
 
+// validateDefaults checks the default of a property, and those of the
+// properties nested in it, against the property's own schema
+func validateDefaults(t *testing.T, path string, property converter.Property) {
+	t.Helper()
+
+	if property.Default != nil {
+		schemaJSON, err := json.Marshal(property)
+		require.NoError(t, err)
+		defaultJSON, err := json.Marshal(property.Default)
+		require.NoError(t, err)
+
+		result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(schemaJSON), gojsonschema.NewBytesLoader(defaultJSON))
+		require.NoError(t, err)
+		for _, resultErr := range result.Errors() {
+			t.Errorf("default of %s does not match its schema: %s", path, resultErr.String())
+		}
+	}
+
+	for name, nested := range property.Properties {
+		validateDefaults(t, path+"."+name, nested)
+	}
+	switch items := property.Items.(type) {
+	case *converter.Property:
+		validateDefaults(t, path+"[*]", *items)
+	case []converter.Property:
+		for i, item := range items {
+			validateDefaults(t, fmt.Sprintf("%s[%d]", path, i), item)
+		}
+	}
+	if additional, ok := property.AdditionalProperties.(*converter.Property); ok {
+		validateDefaults(t, path+"[*]", *additional)
+	}
+}
+
 // TestIntegrationTerraformAWSECS tests parsing and converting the terraform-aws-ecs module variables
 func TestIntegrationTerraformAWSECS(t *testing.T) {
 	testdataPath := filepath.Join("..", "testdata", "terraform-aws-ecs", "variables.tf")
@@ -261,10 +296,10 @@ func TestIntegrationTerraformAWSDynamoDBTable(t *testing.T) {
 	assert.Contains(t, schema.Properties, "name")
 	assert.Contains(t, schema.Properties, "billing_mode")
 
-	// Verify property types
-	assert.Equal(t, "boolean", schema.Properties["create_table"].Type)
-	assert.Equal(t, "string", schema.Properties["name"].Type)
-	assert.Equal(t, "string", schema.Properties["billing_mode"].Type)
+	// Verify property types; variables are nullable unless declared otherwise
+	assert.Equal(t, []string{"boolean", "null"}, schema.Properties["create_table"].Type)
+	assert.Equal(t, []string{"string", "null"}, schema.Properties["name"].Type)
+	assert.Equal(t, []string{"string", "null"}, schema.Properties["billing_mode"].Type)
 
 	// Verify descriptions are preserved
 	assert.NotEmpty(t, schema.Properties["create_table"].Description)
@@ -378,6 +413,12 @@ func TestIntegrationBothModules(t *testing.T) {
 			err = metaValidator.ValidateAgainstMetaSchema(schemaJSON)
 			require.NoError(t, err, "Schema should be valid against JSON Schema Draft 7 meta-schema")
 
+			// Every default, including those of object attributes, must be
+			// accepted by the schema it belongs to
+			for name, property := range schema.Properties {
+				validateDefaults(t, name, property)
+			}
+
 			t.Logf("%s: Parsed %d variables, generated schema with %d properties, ✅ meta-schema valid",
 				tc.name, len(result.Variables), len(schema.Properties))
 		})
@@ -0,0 +1,222 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Severity indicates whether a diagnostic prevents a usable result
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic describes a problem found while processing Terraform files
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Summary  string   `json:"summary"`
+	Detail   string   `json:"detail,omitempty"`
+	Subject  *Range   `json:"range,omitempty"`
+	Snippet  *Snippet `json:"snippet,omitempty"`
+}
+
+// Snippet holds the source lines a diagnostic refers to. The highlight
+// offsets are byte offsets into Code marking the subject of the diagnostic
+type Snippet struct {
+	Code                 string `json:"code"`
+	StartLine            int    `json:"start_line"`
+	HighlightStartOffset int    `json:"highlight_start_offset"`
+	HighlightEndOffset   int    `json:"highlight_end_offset"`
+}
+
+// Error returns the diagnostic on a single line, prefixed with its location
+func (d Diagnostic) Error() string {
+	msg := d.Summary
+	if d.Detail != "" {
+		msg = fmt.Sprintf("%s; %s", d.Summary, d.Detail)
+	}
+	if d.Subject != nil {
+		msg = fmt.Sprintf("%s: %s", d.Subject, msg)
+	}
+	return msg
+}
+
+// Diagnostics is a list of diagnostics. A Diagnostics value containing
+// errors can be returned as an error
+type Diagnostics []Diagnostic
+
+// HasErrors reports whether any of the diagnostics is an error
+func (d Diagnostics) HasErrors() bool {
+	for _, diag := range d {
+		if diag.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Errors returns only the error diagnostics
+func (d Diagnostics) Errors() Diagnostics {
+	return d.filter(SeverityError)
+}
+
+// Warnings returns only the warning diagnostics
+func (d Diagnostics) Warnings() Diagnostics {
+	return d.filter(SeverityWarning)
+}
+
+func (d Diagnostics) filter(severity Severity) Diagnostics {
+	var filtered Diagnostics
+	for _, diag := range d {
+		if diag.Severity == severity {
+			filtered = append(filtered, diag)
+		}
+	}
+	return filtered
+}
+
+// Error joins the error diagnostics, falling back to all diagnostics when
+// there are no errors
+func (d Diagnostics) Error() string {
+	diags := d.Errors()
+	if len(diags) == 0 {
+		diags = d
+	}
+
+	switch len(diags) {
+	case 0:
+		return "no diagnostics"
+	case 1:
+		return diags[0].Error()
+	}
+
+	msgs := make([]string, len(diags))
+	for i, diag := range diags {
+		msgs[i] = diag.Error()
+	}
+	return fmt.Sprintf("%d problems:\n- %s", len(diags), strings.Join(msgs, "\n- "))
+}
+
+// newDiagnostic converts an HCL diagnostic, attaching a snippet of the
+// offending source when the file content is available
+func newDiagnostic(diag *hcl.Diagnostic, files map[string]*hcl.File) Diagnostic {
+	d := Diagnostic{
+		Severity: SeverityError,
+		Summary:  diag.Summary,
+		Detail:   diag.Detail,
+	}
+	if diag.Severity == hcl.DiagWarning {
+		d.Severity = SeverityWarning
+	}
+
+	if diag.Subject != nil {
+		subject := newRange(*diag.Subject)
+		d.Subject = &subject
+		if file, ok := files[diag.Subject.Filename]; ok && file != nil {
+			d.Snippet = newSnippet(*diag.Subject, file.Bytes)
+		}
+	}
+
+	return d
+}
+
+// newDiagnostics converts a set of HCL diagnostics
+func newDiagnostics(diags hcl.Diagnostics, files map[string]*hcl.File) Diagnostics {
+	var converted Diagnostics
+	for _, diag := range diags {
+		converted = append(converted, newDiagnostic(diag, files))
+	}
+	return converted
+}
+
+// newSnippet extracts the whole lines covered by rng from src
+func newSnippet(rng hcl.Range, src []byte) *Snippet {
+	if rng.Start.Byte < 0 || rng.End.Byte > len(src) || rng.Start.Byte > rng.End.Byte {
+		return nil
+	}
+
+	start := bytes.LastIndexByte(src[:rng.Start.Byte], '\n') + 1
+	end := len(src)
+	if i := bytes.IndexByte(src[rng.End.Byte:], '\n'); i >= 0 {
+		end = rng.End.Byte + i
+	}
+
+	return &Snippet{
+		Code:                 strings.TrimRight(string(src[start:end]), "\r"),
+		StartLine:            rng.Start.Line,
+		HighlightStartOffset: rng.Start.Byte - start,
+		HighlightEndOffset:   rng.End.Byte - start,
+	}
+}
+
+// WriteDiagnostics renders diagnostics in the style of Terraform's own
+// output, underlining the subject of each diagnostic with carets
+func WriteDiagnostics(w io.Writer, diags Diagnostics) error {
+	var buf bytes.Buffer
+	for _, diag := range diags {
+		writeDiagnostic(&buf, diag)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeDiagnostic(buf *bytes.Buffer, diag Diagnostic) {
+	switch diag.Severity {
+	case SeverityWarning:
+		fmt.Fprintf(buf, "\nWarning: %s\n\n", diag.Summary)
+	default:
+		fmt.Fprintf(buf, "\nError: %s\n\n", diag.Summary)
+	}
+
+	if diag.Subject != nil {
+		fmt.Fprintf(buf, "  on %s line %d:\n", diag.Subject.Filename, diag.Subject.Start.Line)
+		if diag.Snippet != nil {
+			writeSnippet(buf, diag.Snippet)
+		}
+		buf.WriteString("\n")
+	}
+
+	if diag.Detail != "" {
+		fmt.Fprintf(buf, "%s\n", diag.Detail)
+	}
+}
+
+func writeSnippet(buf *bytes.Buffer, snippet *Snippet) {
+	lineStart := 0
+	for i, line := range strings.Split(snippet.Code, "\n") {
+		prefix := fmt.Sprintf("  %4d: ", snippet.StartLine+i)
+		fmt.Fprintf(buf, "%s%s\n", prefix, line)
+
+		// Underline the part of the subject that falls on this line
+		lineEnd := lineStart + len(line)
+		from := max(snippet.HighlightStartOffset, lineStart)
+		to := min(snippet.HighlightEndOffset, lineEnd)
+		if from < to || (from == to && i == 0) {
+			width := max(len([]rune(line[from-lineStart:to-lineStart])), 1)
+			pad := strings.Repeat(" ", len(prefix)) + indentFor(line[:from-lineStart])
+			fmt.Fprintf(buf, "%s%s\n", pad, strings.Repeat("^", width))
+		}
+
+		lineStart = lineEnd + 1
+	}
+}
+
+// indentFor returns whitespace that lines up with the end of s, keeping
+// tabs so the carets stay aligned whatever the tab width
+func indentFor(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r == '\t' {
+			b.WriteRune('\t')
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,182 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnostics(t *testing.T) {
+	warning := Diagnostic{Severity: SeverityWarning, Summary: "Deprecated attribute"}
+	failure := Diagnostic{
+		Severity: SeverityError,
+		Summary:  "Invalid type specification",
+		Detail:   `Keyword "lists" is not a valid type constructor.`,
+		Subject: &Range{
+			Filename: "variables.tf",
+			Start:    Pos{Line: 2, Column: 10, Byte: 20},
+			End:      Pos{Line: 2, Column: 15, Byte: 25},
+		},
+	}
+
+	t.Run("severity filters", func(t *testing.T) {
+		diags := Diagnostics{warning, failure}
+		assert.True(t, diags.HasErrors())
+		assert.Equal(t, Diagnostics{failure}, diags.Errors())
+		assert.Equal(t, Diagnostics{warning}, diags.Warnings())
+
+		assert.False(t, Diagnostics{warning}.HasErrors())
+		assert.False(t, Diagnostics(nil).HasErrors())
+	})
+
+	t.Run("error messages", func(t *testing.T) {
+		assert.Equal(t,
+			`variables.tf:2,10-2,15: Invalid type specification; Keyword "lists" is not a valid type constructor.`,
+			failure.Error())
+		assert.Equal(t, "Deprecated attribute", warning.Error())
+
+		// Only errors are listed once there are any
+		assert.Equal(t, failure.Error(), Diagnostics{warning, failure}.Error())
+
+		multi := Diagnostics{failure, failure}.Error()
+		assert.True(t, strings.HasPrefix(multi, "2 problems:"))
+	})
+
+	t.Run("usable as an error", func(t *testing.T) {
+		var err error = Diagnostics{failure}
+
+		var diags Diagnostics
+		require.True(t, errors.As(err, &diags))
+		assert.Len(t, diags, 1)
+	})
+}
+
+func TestNewSnippet(t *testing.T) {
+	src := []byte("variable \"x\" {\n  type = lists(string)\n}\n")
+
+	t.Run("single line subject", func(t *testing.T) {
+		rng := hcl.Range{
+			Filename: "main.tf",
+			Start:    hcl.Pos{Line: 2, Column: 10, Byte: 24},
+			End:      hcl.Pos{Line: 2, Column: 15, Byte: 29},
+		}
+
+		snippet := newSnippet(rng, src)
+		require.NotNil(t, snippet)
+		assert.Equal(t, "  type = lists(string)", snippet.Code)
+		assert.Equal(t, 2, snippet.StartLine)
+		assert.Equal(t, "lists", snippet.Code[snippet.HighlightStartOffset:snippet.HighlightEndOffset])
+	})
+
+	t.Run("multi-line subject", func(t *testing.T) {
+		rng := hcl.Range{
+			Filename: "main.tf",
+			Start:    hcl.Pos{Line: 1, Column: 1, Byte: 0},
+			End:      hcl.Pos{Line: 3, Column: 2, Byte: len(src) - 1},
+		}
+
+		snippet := newSnippet(rng, src)
+		require.NotNil(t, snippet)
+		assert.Equal(t, strings.TrimSuffix(string(src), "\n"), snippet.Code)
+	})
+
+	t.Run("out of bounds subject", func(t *testing.T) {
+		rng := hcl.Range{
+			Start: hcl.Pos{Line: 9, Column: 1, Byte: 500},
+			End:   hcl.Pos{Line: 9, Column: 5, Byte: 504},
+		}
+		assert.Nil(t, newSnippet(rng, src))
+	})
+}
+
+func TestWriteDiagnostics(t *testing.T) {
+	t.Run("caret under the subject", func(t *testing.T) {
+		_, err := NewParser().ParseFiles(map[string]io.Reader{
+			"variables.tf": strings.NewReader("variable \"x\" {\n  type = lists(string)\n}\n"),
+		})
+
+		var diags Diagnostics
+		require.True(t, errors.As(err, &diags))
+
+		var buf bytes.Buffer
+		require.NoError(t, WriteDiagnostics(&buf, diags))
+
+		expected := `
+Error: Invalid type specification
+
+  on variables.tf line 2:
+     2:   type = lists(string)
+                 ^^^^^
+
+Keyword "lists" is not a valid type constructor.
+`
+		assert.Equal(t, expected, buf.String())
+	})
+
+	t.Run("tabs are kept in the indentation", func(t *testing.T) {
+		diags := Diagnostics{{
+			Severity: SeverityWarning,
+			Summary:  "Odd value",
+			Subject:  &Range{Filename: "main.tf", Start: Pos{Line: 4}},
+			Snippet: &Snippet{
+				Code:                 "\tvalue = 1",
+				StartLine:            4,
+				HighlightStartOffset: 9,
+				HighlightEndOffset:   10,
+			},
+		}}
+
+		var buf bytes.Buffer
+		require.NoError(t, WriteDiagnostics(&buf, diags))
+		assert.Contains(t, buf.String(), "Warning: Odd value")
+		assert.Contains(t, buf.String(), "\n        \t        ^\n")
+	})
+
+	t.Run("diagnostic without subject", func(t *testing.T) {
+		diags := Diagnostics{{
+			Severity: SeverityError,
+			Summary:  "Failed to read file",
+			Detail:   "The file could not be read.",
+		}}
+
+		var buf bytes.Buffer
+		require.NoError(t, WriteDiagnostics(&buf, diags))
+		assert.Equal(t, "\nError: Failed to read file\n\nThe file could not be read.\n", buf.String())
+	})
+}
+
+func TestParseFiles_Diagnostics(t *testing.T) {
+	t.Run("syntax errors carry range and snippet", func(t *testing.T) {
+		result, err := NewParser().ParseFiles(map[string]io.Reader{
+			"broken.tf": strings.NewReader("variable \"x\" {\n  type = string\n"),
+			"valid.tf":  strings.NewReader("variable \"y\" {}\n"),
+		})
+		require.Error(t, err)
+		require.NotNil(t, result)
+
+		// Files without errors are still extracted
+		require.Len(t, result.Variables, 1)
+		assert.Equal(t, "y", result.Variables[0].Name)
+
+		require.NotEmpty(t, result.Diagnostics)
+		diag := result.Diagnostics[0]
+		assert.Equal(t, SeverityError, diag.Severity)
+		require.NotNil(t, diag.Subject)
+		assert.Equal(t, "broken.tf", diag.Subject.Filename)
+		assert.NotNil(t, diag.Snippet)
+	})
+
+	t.Run("no error without error diagnostics", func(t *testing.T) {
+		result, err := NewParser().ParseFiles(map[string]io.Reader{
+			"valid.tf": strings.NewReader("variable \"y\" {}\n"),
+		})
+		require.NoError(t, err)
+		assert.Empty(t, result.Diagnostics)
+	})
+}
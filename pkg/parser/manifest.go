@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultModuleManifest is where terraform init writes the module manifest,
+// relative to the root module
+var DefaultModuleManifest = filepath.Join(".terraform", "modules", "modules.json")
+
+// defaultRegistryHost is the host Terraform adds to registry sources that
+// do not name one
+const defaultRegistryHost = "registry.terraform.io/"
+
+// ModuleManifest lists the modules terraform init installed
+type ModuleManifest struct {
+	Modules []ModuleManifestEntry `json:"Modules"`
+}
+
+// ModuleManifestEntry records where a module call's module was installed
+type ModuleManifestEntry struct {
+	// Key is the module call's address, such as "network.subnets", or ""
+	// for the root module
+	Key     string `json:"Key"`
+	Source  string `json:"Source"`
+	Version string `json:"Version,omitempty"`
+
+	// Dir is the module's directory, relative to the root module
+	Dir string `json:"Dir"`
+}
+
+// ReadModuleManifest reads a module manifest such as
+// .terraform/modules/modules.json
+func ReadModuleManifest(path string) (*ModuleManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module manifest: %w", err)
+	}
+
+	var manifest ModuleManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse module manifest %s: %w", path, err)
+	}
+	return &manifest, nil
+}
+
+// sameModuleSource reports whether a source recorded in the manifest is the
+// one a module block asks for. The manifest spells out the registry host
+// that registry sources leave implicit
+func sameModuleSource(installed, source string) bool {
+	return strings.TrimPrefix(installed, defaultRegistryHost) == strings.TrimPrefix(source, defaultRegistryHost)
+}
@@ -0,0 +1,122 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// installedModulesConfig is a root module whose registry and git modules
+// were installed by terraform init
+var installedModulesConfig = map[string]string{
+	"main.tf": `
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.0.0"
+}
+
+module "dns" {
+  source = "git::https://example.com/dns.git?ref=v1"
+}
+
+module "network" {
+  source = "./modules/network"
+}
+
+module "cdn" {
+  source = "example/cdn/aws"
+}
+
+module "queue" {
+  source = "example/queue/aws"
+}
+`,
+	"modules/network/main.tf": `
+variable "cidr" {
+  type = string
+}
+`,
+	".terraform/modules/modules.json": `{"Modules":[
+  {"Key":"","Source":"","Dir":"."},
+  {"Key":"vpc","Source":"registry.terraform.io/terraform-aws-modules/vpc/aws","Version":"5.0.0","Dir":".terraform/modules/vpc"},
+  {"Key":"vpc.flow_logs","Source":"./modules/flow-logs","Dir":".terraform/modules/vpc/modules/flow-logs"},
+  {"Key":"dns","Source":"git::https://example.com/dns.git?ref=v1","Dir":".terraform/modules/dns"},
+  {"Key":"network","Source":"./modules/network","Dir":"modules/network"},
+  {"Key":"queue","Source":"registry.terraform.io/example/old-queue/aws","Dir":".terraform/modules/queue"}
+]}`,
+	".terraform/modules/vpc/variables.tf": `
+variable "cidr" {
+  type = string
+}
+
+module "flow_logs" {
+  source = "./modules/flow-logs"
+}
+`,
+	".terraform/modules/vpc/modules/flow-logs/main.tf": `
+variable "retention" {
+  type    = number
+  default = 7
+}
+
+output "log_group" {
+  value = "logs"
+}
+`,
+	".terraform/modules/dns/main.tf.json": `{
+  "variable": { "zone": { "type": "string" } },
+  "output": { "zone_id": { "value": "z" } }
+}`,
+}
+
+func TestLoadModules_WithManifest(t *testing.T) {
+	root := writeModules(t, installedModulesConfig)
+	manifest, err := ReadModuleManifest(filepath.Join(root, DefaultModuleManifest))
+	require.NoError(t, err)
+	require.Len(t, manifest.Modules, 6)
+	assert.Equal(t, "5.0.0", manifest.Modules[1].Version)
+
+	p := NewParser()
+	result := parseModuleDir(t, p, root)
+	diags := p.LoadModules(result, root, WithModuleManifest(manifest))
+
+	vpc := result.Modules[0]
+	assert.Equal(t, filepath.Join(root, ".terraform", "modules", "vpc"), vpc.Dir)
+	require.NotNil(t, vpc.Config)
+	assert.Equal(t, "cidr", vpc.Config.Variables[0].Name)
+
+	// Local modules of installed modules are found like any other
+	flowLogs := vpc.Config.Modules[0]
+	require.NotNil(t, flowLogs.Config)
+	assert.Equal(t, "log_group", flowLogs.Config.Outputs[0].Name)
+
+	dns := result.Modules[1]
+	require.NotNil(t, dns.Config)
+	assert.Equal(t, "zone", dns.Config.Variables[0].Name)
+
+	network := result.Modules[2]
+	assert.Equal(t, filepath.Join(root, "modules", "network"), network.Dir)
+	require.NotNil(t, network.Config)
+
+	require.Len(t, diags, 2)
+	assert.Equal(t, SeverityWarning, diags[0].Severity)
+	assert.Equal(t, "Module not installed", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, `"cdn"`)
+	assert.Nil(t, result.Modules[3].Config)
+
+	assert.Equal(t, "Module source has changed", diags[1].Summary)
+	assert.Contains(t, diags[1].Detail, "registry.terraform.io/example/old-queue/aws was installed")
+	assert.Nil(t, result.Modules[4].Config)
+}
+
+func TestReadModuleManifest_Errors(t *testing.T) {
+	root := writeModules(t, map[string]string{"modules.json": "{"})
+
+	_, err := ReadModuleManifest(filepath.Join(root, "missing.json"))
+	assert.ErrorContains(t, err, "failed to read module manifest")
+
+	_, err = ReadModuleManifest(filepath.Join(root, "modules.json"))
+	assert.ErrorContains(t, err, "failed to parse module manifest")
+}
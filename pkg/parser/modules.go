@@ -0,0 +1,227 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// ModuleOption configures how LoadModules finds the modules it loads
+type ModuleOption func(*moduleLoader)
+
+// WithModuleManifest resolves module sources through the manifest that
+// terraform init writes, so that registry and git modules are loaded from
+// where they were downloaded. Modules the manifest lists with a different
+// source, or remote modules it does not list, are reported as warnings
+func WithModuleManifest(manifest *ModuleManifest) ModuleOption {
+	return func(l *moduleLoader) {
+		l.manifest = make(map[string]ModuleManifestEntry, len(manifest.Modules))
+		for _, entry := range manifest.Modules {
+			l.manifest[entry.Key] = entry
+		}
+	}
+}
+
+type moduleLoader struct {
+	parser      *Parser
+	rootDir     string
+	manifest    map[string]ModuleManifestEntry
+	diagnostics Diagnostics
+}
+
+// moduleCall is a module on the chain of calls being loaded
+type moduleCall struct {
+	key string
+	dir string
+}
+
+// LoadModules parses the local modules called by a configuration read from
+// dir, following their module blocks in turn, and stores each module's
+// configuration and directory on its module block. Modules with other
+// sources, such as the registry or git, are left unloaded unless they are
+// found through WithModuleManifest.
+//
+// Directories that cannot be read and modules that end up calling
+// themselves are reported as error diagnostics, which are returned and also
+// added to result along with those of the loaded modules
+func (p *Parser) LoadModules(result *ParseResult, dir string, opts ...ModuleOption) Diagnostics {
+	l := &moduleLoader{
+		parser:  p,
+		rootDir: dir,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	l.load(result, []moduleCall{{dir: dir}})
+
+	result.Diagnostics = append(result.Diagnostics, l.diagnostics...)
+	return l.diagnostics
+}
+
+// load loads the modules called by the configuration at the end of chain
+func (l *moduleLoader) load(result *ParseResult, chain []moduleCall) {
+	parent := chain[len(chain)-1]
+
+	for i := range result.Modules {
+		module := &result.Modules[i]
+		key := module.Name
+		if parent.key != "" {
+			key = parent.key + "." + module.Name
+		}
+
+		dir, ok := l.resolve(parent.dir, key, module)
+		if !ok {
+			continue
+		}
+		module.Dir = dir
+
+		if caller := callerOf(chain, dir); caller != nil {
+			l.report(hcl.DiagError, module, "Module cycle", fmt.Sprintf("The module %q calls %s, which is %s, so loading it would never end.", module.Name, module.Source, caller.describe()))
+			continue
+		}
+
+		files, err := readModuleDirectory(dir)
+		if err != nil {
+			l.report(hcl.DiagError, module, "Unreadable module directory", fmt.Sprintf("The directory %s of module %q could not be read: %s.", dir, module.Name, err))
+			continue
+		}
+		if len(files) == 0 {
+			l.report(hcl.DiagWarning, module, "Empty module directory", fmt.Sprintf("The directory %s of module %q contains no Terraform configuration files.", dir, module.Name))
+		}
+
+		// Syntax errors are reported through the loaded configuration's
+		// diagnostics, so the returned error carries nothing new
+		config, _ := l.parser.ParseFiles(files)
+		l.diagnostics = append(l.diagnostics, config.Diagnostics...)
+		module.Config = config
+
+		l.load(config, append(chain[:len(chain):len(chain)], moduleCall{key: key, dir: dir}))
+	}
+}
+
+// resolve returns the directory of the module a module block calls, or
+// false when the module is not available on disk
+func (l *moduleLoader) resolve(parentDir, key string, module *Module) (string, bool) {
+	if l.manifest != nil {
+		entry, ok := l.manifest[key]
+		switch {
+		case ok && sameModuleSource(entry.Source, module.Source):
+			return filepath.Join(l.rootDir, filepath.FromSlash(entry.Dir)), true
+		case ok:
+			l.report(hcl.DiagWarning, module, "Module source has changed", fmt.Sprintf("The source of module %q is %s, but %s was installed. Run \"terraform init\" to install the module again.", module.Name, module.Source, entry.Source))
+		case !IsLocalModuleSource(module.Source):
+			l.report(hcl.DiagWarning, module, "Module not installed", fmt.Sprintf("The module %q from %s is not in the module manifest. Run \"terraform init\" to install it.", module.Name, module.Source))
+		}
+	}
+
+	// Local paths are resolved against the directory of the calling module
+	if IsLocalModuleSource(module.Source) {
+		return filepath.Join(parentDir, filepath.FromSlash(module.Source)), true
+	}
+	return "", false
+}
+
+// report records a problem with a module block
+func (l *moduleLoader) report(severity hcl.DiagnosticSeverity, module *Module, summary, detail string) {
+	l.diagnostics = append(l.diagnostics, newDiagnostic(&hcl.Diagnostic{
+		Severity: severity,
+		Summary:  summary,
+		Detail:   detail,
+		Subject:  moduleSubject(module),
+	}, l.parser.parser.Files()))
+}
+
+// moduleSubject points at the header line of a module block
+func moduleSubject(module *Module) *hcl.Range {
+	start := hcl.Pos{Line: module.Range.Start.Line, Column: module.Range.Start.Column, Byte: module.Range.Start.Byte}
+	return &hcl.Range{Filename: module.Range.Filename, Start: start, End: start}
+}
+
+// callerOf returns the call on the chain that loaded dir, or nil
+func callerOf(chain []moduleCall, dir string) *moduleCall {
+	abs := absPath(dir)
+	for i := range chain {
+		if absPath(chain[i].dir) == abs {
+			return &chain[i]
+		}
+	}
+	return nil
+}
+
+// describe names the module a call loaded
+func (c *moduleCall) describe() string {
+	if c.key == "" {
+		return "the root module"
+	}
+	return fmt.Sprintf("module.%s", strings.ReplaceAll(c.key, ".", ".module."))
+}
+
+// IsLocalModuleSource reports whether a module source is a local path
+// rather than a registry address or remote location
+func IsLocalModuleSource(source string) bool {
+	for _, prefix := range []string{"./", "../", `.\`, `..\`} {
+		if strings.HasPrefix(source, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// readModuleDirectory reads the .tf and .tf.json files of a module
+func readModuleDirectory(dir string) (map[string]io.Reader, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]io.Reader)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || (!strings.HasSuffix(name, ".tf") && !strings.HasSuffix(name, ".tf.json")) {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		files[path] = bytes.NewReader(content)
+	}
+	return files, nil
+}
+
+// absPath returns an absolute, cleaned form of path for comparisons
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// WalkModules calls fn for every module block in the tree of loaded
+// modules below result, callers before the modules they call. key is the
+// module's address, such as "network.subnets"
+func (r *ParseResult) WalkModules(fn func(key string, module *Module)) {
+	walkModules(r, "", fn)
+}
+
+func walkModules(result *ParseResult, parent string, fn func(key string, module *Module)) {
+	for i := range result.Modules {
+		module := &result.Modules[i]
+		key := module.Name
+		if parent != "" {
+			key = parent + "." + module.Name
+		}
+		fn(key, module)
+		if module.Config != nil {
+			walkModules(module.Config, key, fn)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeModules writes files, keyed by slash-separated paths, below a
+// temporary directory and returns the directory
+func writeModules(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return root
+}
+
+// parseModuleDir parses the configuration files of a module directory
+func parseModuleDir(t *testing.T, p *Parser, dir string) *ParseResult {
+	t.Helper()
+
+	files, err := readModuleDirectory(dir)
+	require.NoError(t, err)
+	result, err := p.ParseFiles(files)
+	require.NoError(t, err)
+	return result
+}
+
+func TestLoadModules(t *testing.T) {
+	root := writeModules(t, map[string]string{
+		"stack/main.tf": `
+module "network" {
+  source = "./modules/network"
+}
+
+module "iam" {
+  source = "../shared/iam"
+}
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.0.0"
+}
+`,
+		"stack/modules/network/main.tf": `
+variable "cidr" {
+  type = string
+}
+
+output "vpc_id" {
+  value = "vpc-123"
+}
+
+module "subnets" {
+  source = "./subnets"
+}
+`,
+		"stack/modules/network/subnets/main.tf": `
+variable "count_per_az" {
+  type    = number
+  default = 1
+}
+`,
+		"shared/iam/main.tf.json": `{
+  "variable": { "role_name": { "type": "string" } },
+  "output": { "role_arn": { "value": "arn" } }
+}`,
+	})
+	stack := filepath.Join(root, "stack")
+
+	p := NewParser()
+	result := parseModuleDir(t, p, stack)
+	diags := p.LoadModules(result, stack)
+	assert.Empty(t, diags)
+
+	require.Len(t, result.Modules, 3)
+	network := result.Modules[0]
+	assert.Equal(t, filepath.Join(stack, "modules", "network"), network.Dir)
+	require.NotNil(t, network.Config)
+	assert.Equal(t, "cidr", network.Config.Variables[0].Name)
+	assert.Equal(t, "vpc_id", network.Config.Outputs[0].Name)
+
+	require.Len(t, network.Config.Modules, 1)
+	subnets := network.Config.Modules[0]
+	require.NotNil(t, subnets.Config)
+	assert.Equal(t, "count_per_az", subnets.Config.Variables[0].Name)
+
+	iam := result.Modules[1]
+	assert.Equal(t, filepath.Join(root, "shared", "iam"), iam.Dir)
+	require.NotNil(t, iam.Config)
+	assert.Equal(t, "role_name", iam.Config.Variables[0].Name)
+	assert.Equal(t, "role_arn", iam.Config.Outputs[0].Name)
+
+	// Registry modules are not local, so they are left alone
+	assert.Empty(t, result.Modules[2].Dir)
+	assert.Nil(t, result.Modules[2].Config)
+
+	var keys []string
+	result.WalkModules(func(key string, module *Module) {
+		keys = append(keys, key)
+	})
+	assert.Equal(t, []string{"network", "network.subnets", "iam", "vpc"}, keys)
+}
+
+func TestLoadModules_Problems(t *testing.T) {
+	root := writeModules(t, map[string]string{
+		"main.tf": `
+module "missing" {
+  source = "./modules/missing"
+}
+
+module "a" {
+  source = "./modules/a"
+}
+
+module "empty" {
+  source = "./modules/empty"
+}
+`,
+		"modules/a/main.tf": `
+module "b" {
+  source = "../b"
+}
+`,
+		"modules/b/main.tf": `
+module "a" {
+  source = "../a"
+}
+
+module "root" {
+  source = "../.."
+}
+`,
+		"modules/empty/README.md": "Nothing here",
+	})
+
+	p := NewParser()
+	result := parseModuleDir(t, p, root)
+	diags := p.LoadModules(result, root)
+
+	require.Len(t, diags, 4)
+
+	assert.Equal(t, SeverityError, diags[0].Severity)
+	assert.Equal(t, "Unreadable module directory", diags[0].Summary)
+	assert.Equal(t, filepath.Join(root, "main.tf"), diags[0].Subject.Filename)
+	assert.Equal(t, 2, diags[0].Subject.Start.Line)
+	require.NotNil(t, diags[0].Snippet)
+	assert.Equal(t, `module "missing" {`, diags[0].Snippet.Code)
+
+	assert.Equal(t, "Module cycle", diags[1].Summary)
+	assert.Equal(t, `The module "a" calls ../a, which is module.a, so loading it would never end.`, diags[1].Detail)
+	assert.Equal(t, filepath.Join(root, "modules", "b", "main.tf"), diags[1].Subject.Filename)
+
+	assert.Equal(t, "Module cycle", diags[2].Summary)
+	assert.Contains(t, diags[2].Detail, "which is the root module")
+
+	assert.Equal(t, SeverityWarning, diags[3].Severity)
+	assert.Equal(t, "Empty module directory", diags[3].Summary)
+
+	// The modules up to the cycle are still loaded
+	require.NotNil(t, result.Modules[1].Config)
+	b := result.Modules[1].Config.Modules[0]
+	require.NotNil(t, b.Config)
+	assert.Nil(t, b.Config.Modules[0].Config)
+
+	assert.Len(t, result.Diagnostics, 4)
+	assert.True(t, result.Diagnostics.HasErrors())
+}
+
+func TestIsLocalModuleSource(t *testing.T) {
+	assert.True(t, IsLocalModuleSource("./modules/network"))
+	assert.True(t, IsLocalModuleSource("../shared/iam"))
+	assert.False(t, IsLocalModuleSource("terraform-aws-modules/vpc/aws"))
+	assert.False(t, IsLocalModuleSource("git::https://example.com/network.git"))
+	assert.False(t, IsLocalModuleSource("modules/network"))
+}
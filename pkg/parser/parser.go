@@ -1,19 +1,25 @@
 package parser
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
 )
 
 // Variable represents a Terraform variable definition
 type Variable struct {
 	Name        string                 `json:"name"`
 	Type        string                 `json:"type"`
+	TypeSpec    *TypeSpec              `json:"type_spec,omitempty"`
 	Description string                 `json:"description,omitempty"`
 	Default     interface{}            `json:"default,omitempty"`
 	Required    bool                   `json:"required"`
@@ -22,12 +28,14 @@ type Variable struct {
 	Ephemeral   bool                   `json:"ephemeral,omitempty"`
 	Validations []Validation           `json:"validations,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Range       Range                  `json:"range"`
 }
 
 // Validation represents a Terraform variable validation block
 type Validation struct {
 	Condition    string `json:"condition"`
 	ErrorMessage string `json:"error_message"`
+	Range        Range  `json:"range"`
 }
 
 // Output represents a Terraform output definition
@@ -37,6 +45,7 @@ type Output struct {
 	Value       string   `json:"value,omitempty"`
 	Sensitive   bool     `json:"sensitive,omitempty"`
 	DependsOn   []string `json:"depends_on,omitempty"`
+	Range       Range    `json:"range"`
 }
 
 // Provider represents a Terraform provider requirement
@@ -44,13 +53,45 @@ type Provider struct {
 	Name    string `json:"name"`
 	Source  string `json:"source,omitempty"`
 	Version string `json:"version,omitempty"`
+	Range   Range  `json:"range"`
 }
 
-// Resource represents a Terraform resource
+// Resource represents a Terraform managed resource or data source
 type Resource struct {
-	Type  string `json:"type"`
-	Name  string `json:"name"`
-	Count int    `json:"count,omitempty"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+
+	// Count and ForEach are the source text of the count and for_each
+	// expressions, such as "var.enabled ? 1 : 0"
+	Count   string `json:"count,omitempty"`
+	ForEach string `json:"for_each,omitempty"`
+
+	// Provider is the provider configuration the resource uses, such as
+	// "aws.west", when it is not the default one
+	Provider  string     `json:"provider,omitempty"`
+	DependsOn []string   `json:"depends_on,omitempty"`
+	Lifecycle *Lifecycle `json:"lifecycle,omitempty"`
+	Range     Range      `json:"range"`
+}
+
+// Lifecycle holds the settings of a resource's lifecycle block
+type Lifecycle struct {
+	CreateBeforeDestroy bool `json:"create_before_destroy,omitempty"`
+	PreventDestroy      bool `json:"prevent_destroy,omitempty"`
+
+	// IgnoreChanges lists the ignored attribute paths as written, or just
+	// "all"
+	IgnoreChanges      []string `json:"ignore_changes,omitempty"`
+	ReplaceTriggeredBy []string `json:"replace_triggered_by,omitempty"`
+}
+
+// Local represents a named value of a locals block
+type Local struct {
+	Name string `json:"name"`
+
+	// Expression is the source text of the value
+	Expression string `json:"expression"`
+	Range      Range  `json:"range"`
 }
 
 // Module represents a module dependency
@@ -58,17 +99,88 @@ type Module struct {
 	Name    string `json:"name"`
 	Source  string `json:"source"`
 	Version string `json:"version,omitempty"`
+	Range   Range  `json:"range"`
+
+	// Count, ForEach and DependsOn are the meta-arguments of the call, as
+	// source text
+	Count     string   `json:"count,omitempty"`
+	ForEach   string   `json:"for_each,omitempty"`
+	DependsOn []string `json:"depends_on,omitempty"`
+
+	// Providers maps the provider configurations of the called module to
+	// those of the caller, such as "aws" to "aws.west"
+	Providers map[string]string `json:"providers,omitempty"`
+
+	// Arguments are the input variable values passed to the module, in
+	// declaration order
+	Arguments []ModuleArgument `json:"arguments,omitempty"`
+
+	// Dir and Config are the directory and parsed configuration of the
+	// called module, set once LoadModules has loaded it
+	Dir    string       `json:"dir,omitempty"`
+	Config *ParseResult `json:"config,omitempty"`
+}
+
+// ModuleArgument is an input variable value set in a module block
+type ModuleArgument struct {
+	Name string `json:"name"`
+
+	// Expression is the source text of the value
+	Expression string `json:"expression"`
+
+	// Value is the value of a literal expression, one that refers to
+	// nothing and calls no functions, and cty.NilVal for anything else
+	Value cty.Value `json:"-"`
+
+	Range Range `json:"range"`
+}
+
+// IsLiteral reports whether the argument's value is known without
+// evaluating references or function calls
+func (a ModuleArgument) IsLiteral() bool {
+	return a.Value != cty.NilVal
+}
+
+// Range describes the location of an element within a Terraform file
+type Range struct {
+	Filename string `json:"filename"`
+	Start    Pos    `json:"start"`
+	End      Pos    `json:"end"`
+}
+
+// Pos is a position within a file. Line and Column are 1-based, while Byte
+// is the 0-based offset into the file content
+type Pos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Byte   int `json:"byte"`
+}
+
+// newRange converts an HCL source range into a Range
+func newRange(rng hcl.Range) Range {
+	return Range{
+		Filename: rng.Filename,
+		Start:    Pos{Line: rng.Start.Line, Column: rng.Start.Column, Byte: rng.Start.Byte},
+		End:      Pos{Line: rng.End.Line, Column: rng.End.Column, Byte: rng.End.Byte},
+	}
+}
+
+// String returns the range in the conventional file:line,column form
+func (r Range) String() string {
+	return fmt.Sprintf("%s:%d,%d-%d,%d", r.Filename, r.Start.Line, r.Start.Column, r.End.Line, r.End.Column)
 }
 
 // ParseResult contains the parsed Terraform variables
 type ParseResult struct {
-	Variables        []Variable `json:"variables"`
-	Outputs          []Output   `json:"outputs,omitempty"`
-	Providers        []Provider `json:"providers,omitempty"`
-	Resources        []Resource `json:"resources,omitempty"`
-	Modules          []Module   `json:"modules,omitempty"`
-	TerraformVersion string     `json:"terraform_version,omitempty"`
-	Errors           []string   `json:"errors,omitempty"`
+	Variables        []Variable  `json:"variables"`
+	Outputs          []Output    `json:"outputs,omitempty"`
+	Providers        []Provider  `json:"providers,omitempty"`
+	Resources        []Resource  `json:"resources,omitempty"`
+	DataSources      []Resource  `json:"data_sources,omitempty"`
+	Locals           []Local     `json:"locals,omitempty"`
+	Modules          []Module    `json:"modules,omitempty"`
+	TerraformVersion string      `json:"terraform_version,omitempty"`
+	Diagnostics      Diagnostics `json:"diagnostics,omitempty"`
 }
 
 // Parser handles parsing of Terraform files
@@ -83,52 +195,217 @@ func NewParser() *Parser {
 	}
 }
 
-// ParseFiles parses multiple Terraform files and extracts all components
+// rootSchema lists the top-level blocks extracted from a configuration file
+var rootSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "variable", LabelNames: []string{"name"}},
+		{Type: "output", LabelNames: []string{"name"}},
+		{Type: "resource", LabelNames: []string{"type", "name"}},
+		{Type: "data", LabelNames: []string{"type", "name"}},
+		{Type: "locals"},
+		{Type: "module", LabelNames: []string{"name"}},
+		{Type: "terraform"},
+	},
+}
+
+var variableSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "type"},
+		{Name: "description"},
+		{Name: "default"},
+		{Name: "sensitive"},
+		{Name: "nullable"},
+		{Name: "ephemeral"},
+	},
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "validation"},
+	},
+}
+
+var validationSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "condition"},
+		{Name: "error_message"},
+	},
+}
+
+var outputSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "description"},
+		{Name: "value"},
+		{Name: "sensitive"},
+		{Name: "depends_on"},
+	},
+}
+
+var resourceSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "count"},
+		{Name: "for_each"},
+		{Name: "provider"},
+		{Name: "depends_on"},
+	},
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "lifecycle"},
+	},
+}
+
+var lifecycleSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "create_before_destroy"},
+		{Name: "prevent_destroy"},
+		{Name: "ignore_changes"},
+		{Name: "replace_triggered_by"},
+	},
+}
+
+var moduleSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "source"},
+		{Name: "version"},
+		{Name: "count"},
+		{Name: "for_each"},
+		{Name: "depends_on"},
+		{Name: "providers"},
+	},
+}
+
+// moduleMetaArguments are the module block attributes that configure the
+// call itself rather than set input variables
+var moduleMetaArguments = map[string]bool{
+	"source":     true,
+	"version":    true,
+	"count":      true,
+	"for_each":   true,
+	"providers":  true,
+	"depends_on": true,
+}
+
+var terraformSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "required_version"},
+	},
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "required_providers"},
+	},
+}
+
+// ParseFiles parses multiple Terraform files and extracts all components.
+// Files ending in .tf.json are read as Terraform JSON syntax, other JSON
+// files are skipped with a warning, as Terraform ignores them, and
+// everything else is read as native HCL. Problems are recorded as
+// diagnostics on the result; when any of them is an error the diagnostics
+// are also returned as the error, alongside whatever could still be
+// extracted
 func (p *Parser) ParseFiles(files map[string]io.Reader) (*ParseResult, error) {
 	result := &ParseResult{
-		Variables: []Variable{},
-		Outputs:   []Output{},
-		Providers: []Provider{},
-		Resources: []Resource{},
-		Modules:   []Module{},
-		Errors:    []string{},
+		Variables:   []Variable{},
+		Outputs:     []Output{},
+		Providers:   []Provider{},
+		Resources:   []Resource{},
+		DataSources: []Resource{},
+		Locals:      []Local{},
+		Modules:     []Module{},
 	}
 
-	for filename, reader := range files {
+	// Visit files in name order so results are identical across runs
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
 		// Read file content
-		content, err := io.ReadAll(reader)
+		content, err := io.ReadAll(files[filename])
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("Failed to read %s: %v", filename, err))
+			result.Diagnostics = append(result.Diagnostics, Diagnostic{
+				Severity: SeverityError,
+				Summary:  "Failed to read file",
+				Detail:   fmt.Sprintf("The file %q could not be read: %s.", filename, err),
+			})
 			continue
 		}
 
-		// Parse HCL file
-		file, diags := p.parser.ParseHCL(content, filename)
-		if diags.HasErrors() {
-			result.Errors = append(result.Errors, fmt.Sprintf("Parse errors in %s: %s", filename, diags.Error()))
+		if strings.HasSuffix(filename, ".json") && !IsJSONFilename(filename) {
+			result.Diagnostics = append(result.Diagnostics, Diagnostic{
+				Severity: SeverityWarning,
+				Summary:  "Not a configuration file",
+				Detail:   fmt.Sprintf("The file %q was skipped: Terraform only reads JSON configuration from files ending in .tf.json.", filename),
+			})
 			continue
 		}
 
-		// Extract all components from the parsed file
-		vars, err := p.extractVariables(file)
-		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("Failed to extract variables from %s: %v", filename, err))
+		// Parse the file in whichever syntax its name indicates
+		var file *hcl.File
+		var diags hcl.Diagnostics
+		if IsJSONFilename(filename) {
+			file, diags = p.parser.ParseJSON(content, filename)
 		} else {
-			result.Variables = append(result.Variables, vars...)
+			file, diags = p.parser.ParseHCL(content, filename)
+		}
+		result.Diagnostics = append(result.Diagnostics, newDiagnostics(diags, p.parser.Files())...)
+		if diags.HasErrors() {
+			continue
 		}
 
-		outputs := p.extractOutputs(file)
-		result.Outputs = append(result.Outputs, outputs...)
+		// Extract all components from the parsed file
+		diags = p.extractFile(file, result)
+		result.Diagnostics = append(result.Diagnostics, newDiagnostics(diags, p.parser.Files())...)
+	}
 
-		resources := p.extractResources(file)
-		result.Resources = append(result.Resources, resources...)
+	if result.Diagnostics.HasErrors() {
+		return result, result.Diagnostics
+	}
+	return result, nil
+}
 
-		modules := p.extractModules(file)
-		result.Modules = append(result.Modules, modules...)
+// IsJSONFilename reports whether a file should be read as Terraform JSON
+// syntax rather than native HCL, e.g. main.tf.json. Other JSON files, such
+// as package.json, are not Terraform configuration
+func IsJSONFilename(filename string) bool {
+	return strings.HasSuffix(filename, ".tf.json")
+}
 
-		// Extract terraform block for version and providers
-		if filename == "versions.tf" || strings.Contains(string(content), "terraform {") {
-			tfVersion, providers := p.extractTerraformBlock(file)
+// extractFile extracts all supported blocks of a parsed file into result
+func (p *Parser) extractFile(file *hcl.File, result *ParseResult) hcl.Diagnostics {
+	content, _, diags := file.Body.PartialContent(rootSchema)
+
+	for _, block := range content.Blocks {
+		switch block.Type {
+		case "variable":
+			variable, varDiags := p.extractVariable(block, file)
+			diags = append(diags, varDiags...)
+			result.Variables = append(result.Variables, variable)
+
+		case "output":
+			output, outDiags := p.extractOutput(block, file)
+			diags = append(diags, outDiags...)
+			result.Outputs = append(result.Outputs, output)
+
+		case "resource":
+			resource, resDiags := p.extractResource(block, file)
+			diags = append(diags, resDiags...)
+			result.Resources = append(result.Resources, resource)
+
+		case "data":
+			dataSource, dataDiags := p.extractResource(block, file)
+			diags = append(diags, dataDiags...)
+			result.DataSources = append(result.DataSources, dataSource)
+
+		case "locals":
+			locals, localDiags := p.extractLocals(block, file)
+			diags = append(diags, localDiags...)
+			result.Locals = append(result.Locals, locals...)
+
+		case "module":
+			module, modDiags := p.extractModule(block, file)
+			diags = append(diags, modDiags...)
+			result.Modules = append(result.Modules, module)
+
+		case "terraform":
+			tfVersion, providers, tfDiags := p.extractTerraformBlock(block)
+			diags = append(diags, tfDiags...)
 			if tfVersion != "" {
 				result.TerraformVersion = tfVersion
 			}
@@ -136,99 +413,158 @@ func (p *Parser) ParseFiles(files map[string]io.Reader) (*ParseResult, error) {
 		}
 	}
 
-	return result, nil
+	return diags
 }
 
-// extractVariables extracts variable blocks from an HCL file
-func (p *Parser) extractVariables(file *hcl.File) ([]Variable, error) {
-	variables := []Variable{}
+// extractVariable extracts a variable block
+func (p *Parser) extractVariable(block *hcl.Block, file *hcl.File) (Variable, hcl.Diagnostics) {
+	variable := Variable{
+		Name:     block.Labels[0],
+		Required: true, // Default to required unless default is set
+		Metadata: make(map[string]interface{}),
+		Range:    newRange(blockRange(block)),
+	}
+
+	content, _, diags := block.Body.PartialContent(variableSchema)
 
-	body, ok := file.Body.(*hclsyntax.Body)
-	if !ok {
-		return variables, fmt.Errorf("unexpected body type")
+	// Extract variable attributes
+	if typeAttr, exists := content.Attributes["type"]; exists {
+		expr, typeStr, exprDiags := typeExpression(typeAttr.Expr, file)
+		diags = append(diags, exprDiags...)
+		if expr != nil {
+			variable.Type = typeStr
+			spec, typeDiags := p.parseTypeSpec(expr)
+			diags = append(diags, typeDiags...)
+			variable.TypeSpec = spec
+		}
 	}
 
-	for _, block := range body.Blocks {
-		if block.Type != "variable" {
-			continue
+	if descAttr, exists := content.Attributes["description"]; exists {
+		if val, ok := literalString(descAttr.Expr); ok {
+			variable.Description = val
 		}
+	}
 
-		if len(block.Labels) == 0 {
-			continue
+	if defaultAttr, exists := content.Attributes["default"]; exists {
+		variable.Required = false
+		val, valDiags := defaultAttr.Expr.Value(nil)
+		if valDiags.HasErrors() {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid default value for variable",
+				Detail:   fmt.Sprintf("The default value for variable %q must be a literal value: %s", variable.Name, valDiags.Error()),
+				Subject:  defaultAttr.Expr.Range().Ptr(),
+			})
+		} else {
+			// Convert to the declared type and apply optional attribute
+			// defaults, so the default matches what Terraform would use
+			var err error
+			if variable.TypeSpec != nil {
+				val, err = ConformValue(val, variable.TypeSpec)
+			}
+			if err == nil {
+				variable.Default, err = ConvertCtyValue(val)
+			}
+			if err != nil {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid default value for variable",
+					Detail:   fmt.Sprintf("The default value for variable %q is not compatible with its type constraint: %s.", variable.Name, err),
+					Subject:  defaultAttr.Expr.Range().Ptr(),
+				})
+			}
 		}
+	}
 
-		variable := Variable{
-			Name:     block.Labels[0],
-			Required: true, // Default to required unless default is set
-			Metadata: make(map[string]interface{}),
+	if sensitiveAttr, exists := content.Attributes["sensitive"]; exists {
+		if val, ok := literalBool(sensitiveAttr.Expr); ok {
+			variable.Sensitive = val
 		}
+	}
 
-		// Extract variable attributes
-		if typeAttr, exists := block.Body.Attributes["type"]; exists {
-			variable.Type = p.extractTypeString(typeAttr.Expr, file.Bytes)
+	// Default value for nullable is true according to Terraform spec
+	variable.Nullable = true
+	if nullableAttr, exists := content.Attributes["nullable"]; exists {
+		if val, ok := literalBool(nullableAttr.Expr); ok {
+			variable.Nullable = val
 		}
+	}
 
-		if descAttr, exists := block.Body.Attributes["description"]; exists {
-			if val, diags := descAttr.Expr.Value(nil); !diags.HasErrors() {
-				variable.Description = val.AsString()
-			}
+	// Terraform rejects a null default that the variable could never hold
+	if defaultAttr, exists := content.Attributes["default"]; exists && !variable.Nullable {
+		if val, valDiags := defaultAttr.Expr.Value(nil); !valDiags.HasErrors() && val.IsNull() {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid default value for variable",
+				Detail:   "A null default value is not valid when nullable=false.",
+				Subject:  defaultAttr.Expr.Range().Ptr(),
+			})
 		}
+	}
 
-		if defaultAttr, exists := block.Body.Attributes["default"]; exists {
-			variable.Required = false
-			if val, diags := defaultAttr.Expr.Value(nil); !diags.HasErrors() {
-				variable.Default = p.convertCtyValue(val)
-			}
+	if ephemeralAttr, exists := content.Attributes["ephemeral"]; exists {
+		if val, ok := literalBool(ephemeralAttr.Expr); ok {
+			variable.Ephemeral = val
 		}
+	}
 
-		if sensitiveAttr, exists := block.Body.Attributes["sensitive"]; exists {
-			if val, diags := sensitiveAttr.Expr.Value(nil); !diags.HasErrors() {
-				variable.Sensitive = val.True()
-			}
+	// Extract validation blocks
+	for _, validationBlock := range content.Blocks.OfType("validation") {
+		rule := Validation{
+			Range: newRange(blockRange(validationBlock)),
 		}
 
-		if nullableAttr, exists := block.Body.Attributes["nullable"]; exists {
-			if val, diags := nullableAttr.Expr.Value(nil); !diags.HasErrors() {
-				variable.Nullable = val.True()
-			}
-		} else {
-			// Default value for nullable is true according to Terraform spec
-			variable.Nullable = true
+		validation, _, valDiags := validationBlock.Body.PartialContent(validationSchema)
+		diags = append(diags, valDiags...)
+
+		if condAttr, exists := validation.Attributes["condition"]; exists {
+			rule.Condition = expressionSource(condAttr.Expr, file)
 		}
 
-		if ephemeralAttr, exists := block.Body.Attributes["ephemeral"]; exists {
-			if val, diags := ephemeralAttr.Expr.Value(nil); !diags.HasErrors() {
-				variable.Ephemeral = val.True()
+		if errMsgAttr, exists := validation.Attributes["error_message"]; exists {
+			if val, ok := literalString(errMsgAttr.Expr); ok {
+				rule.ErrorMessage = val
 			}
 		}
 
-		// Extract validation blocks
-		for _, validationBlock := range block.Body.Blocks {
-			if validationBlock.Type == "validation" {
-				rule := Validation{}
-
-				if condAttr, exists := validationBlock.Body.Attributes["condition"]; exists {
-					rule.Condition = string(condAttr.Expr.Range().SliceBytes(file.Bytes))
-				}
+		variable.Validations = append(variable.Validations, rule)
+	}
 
-				if errMsgAttr, exists := validationBlock.Body.Attributes["error_message"]; exists {
-					if val, diags := errMsgAttr.Expr.Value(nil); !diags.HasErrors() {
-						rule.ErrorMessage = val.AsString()
-					}
-				}
+	return variable, diags
+}
 
-				variable.Validations = append(variable.Validations, rule)
-			}
-		}
+// typeExpression returns the expression holding a variable's type
+// constraint along with its source text. The JSON syntax writes type
+// constraints as strings, which are parsed as native HCL expressions
+func typeExpression(expr hcl.Expression, file *hcl.File) (hcl.Expression, string, hcl.Diagnostics) {
+	if !hcljson.IsJSONExpression(expr) {
+		return expr, extractTypeString(expr, file.Bytes), nil
+	}
 
-		variables = append(variables, variable)
+	src, ok := literalString(expr)
+	if !ok {
+		return nil, "", hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid type specification",
+			Detail:   "In JSON syntax, a type constraint must be given as a string containing a type expression, such as \"list(string)\".",
+			Subject:  expr.Range().Ptr(),
+		}}
 	}
 
-	return variables, nil
+	// Start just after the opening quote so ranges point into the file
+	start := expr.Range().Start
+	start.Column++
+	start.Byte++
+
+	native, diags := hclsyntax.ParseExpression([]byte(src), expr.Range().Filename, start)
+	if diags.HasErrors() {
+		return nil, "", diags
+	}
+	return native, strings.TrimSpace(src), nil
 }
 
 // extractTypeString extracts the type as a string from an expression
-func (p *Parser) extractTypeString(expr hclsyntax.Expression, fileBytes []byte) string {
+func extractTypeString(expr hcl.Expression, fileBytes []byte) string {
 	// Handle simple type references like string, number, bool
 	if traversal, ok := expr.(*hclsyntax.ScopeTraversalExpr); ok {
 		parts := []string{}
@@ -255,190 +591,318 @@ func (p *Parser) extractTypeString(expr hclsyntax.Expression, fileBytes []byte)
 	return typeStr
 }
 
-// convertCtyValue converts a cty.Value to a native Go type
-func (p *Parser) convertCtyValue(val interface{}) interface{} {
-	// This is a simplified conversion - in production you'd handle all cty types
-	// For now, return as-is and let JSON marshaling handle it
-	return val
+// expressionSource returns the source text of an expression. For JSON
+// syntax the string is decoded and a lone "${...}" interpolation is
+// unwrapped, so both syntaxes yield the same native expression text
+func expressionSource(expr hcl.Expression, file *hcl.File) string {
+	raw := strings.TrimSpace(string(expr.Range().SliceBytes(file.Bytes)))
+	if !hcljson.IsJSONExpression(expr) {
+		return raw
+	}
+
+	var src string
+	if err := json.Unmarshal([]byte(raw), &src); err != nil {
+		// Numbers, bools, arrays and objects are already valid HCL
+		return raw
+	}
+
+	template, diags := hclsyntax.ParseTemplate([]byte(src), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return src
+	}
+	if wrap, ok := template.(*hclsyntax.TemplateWrapExpr); ok {
+		return strings.TrimSpace(string(wrap.Wrapped.Range().SliceBytes([]byte(src))))
+	}
+	return src
 }
 
-// extractOutputs extracts output blocks from an HCL file
-func (p *Parser) extractOutputs(file *hcl.File) []Output {
-	outputs := []Output{}
+// blockRange returns the range of a whole block, from its type keyword to
+// the end of its body
+func blockRange(block *hcl.Block) hcl.Range {
+	if body, ok := block.Body.(*hclsyntax.Body); ok {
+		return hcl.RangeBetween(block.DefRange, body.SrcRange)
+	}
+	// JSON bodies report their closing brace as the missing item range
+	return hcl.RangeBetween(block.DefRange, block.Body.MissingItemRange())
+}
 
-	body, ok := file.Body.(*hclsyntax.Body)
-	if !ok {
-		return outputs
+// literalString evaluates an expression expected to be a literal string
+func literalString(expr hcl.Expression) (string, bool) {
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() {
+		return "", false
 	}
+	val, err := convert.Convert(val, cty.String)
+	if err != nil {
+		return "", false
+	}
+	return val.AsString(), true
+}
 
-	for _, block := range body.Blocks {
-		if block.Type != "output" {
-			continue
-		}
+// literalBool evaluates an expression expected to be a literal bool
+func literalBool(expr hcl.Expression) (bool, bool) {
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() {
+		return false, false
+	}
+	val, err := convert.Convert(val, cty.Bool)
+	if err != nil {
+		return false, false
+	}
+	return val.True(), true
+}
 
-		if len(block.Labels) == 0 {
-			continue
-		}
+// extractOutput extracts an output block
+func (p *Parser) extractOutput(block *hcl.Block, file *hcl.File) (Output, hcl.Diagnostics) {
+	output := Output{
+		Name:  block.Labels[0],
+		Range: newRange(blockRange(block)),
+	}
 
-		output := Output{
-			Name: block.Labels[0],
-		}
+	content, _, diags := block.Body.PartialContent(outputSchema)
 
-		if descAttr, exists := block.Body.Attributes["description"]; exists {
-			if val, diags := descAttr.Expr.Value(nil); !diags.HasErrors() {
-				output.Description = val.AsString()
-			}
+	if descAttr, exists := content.Attributes["description"]; exists {
+		if val, ok := literalString(descAttr.Expr); ok {
+			output.Description = val
 		}
+	}
 
-		if valAttr, exists := block.Body.Attributes["value"]; exists {
-			output.Value = strings.TrimSpace(string(valAttr.Expr.Range().SliceBytes(file.Bytes)))
-		}
+	if valAttr, exists := content.Attributes["value"]; exists {
+		output.Value = expressionSource(valAttr.Expr, file)
+	}
 
-		if sensitiveAttr, exists := block.Body.Attributes["sensitive"]; exists {
-			if val, diags := sensitiveAttr.Expr.Value(nil); !diags.HasErrors() {
-				output.Sensitive = val.True()
-			}
+	if sensitiveAttr, exists := content.Attributes["sensitive"]; exists {
+		if val, ok := literalBool(sensitiveAttr.Expr); ok {
+			output.Sensitive = val
 		}
+	}
 
-		outputs = append(outputs, output)
+	if dependsAttr, exists := content.Attributes["depends_on"]; exists {
+		output.DependsOn = expressionList(dependsAttr.Expr, file)
 	}
 
-	return outputs
+	return output, diags
 }
 
-// extractResources extracts resource blocks from an HCL file
-func (p *Parser) extractResources(file *hcl.File) []Resource {
-	resources := []Resource{}
+// extractResource extracts a resource or data block
+func (p *Parser) extractResource(block *hcl.Block, file *hcl.File) (Resource, hcl.Diagnostics) {
+	resource := Resource{
+		Type:  block.Labels[0],
+		Name:  block.Labels[1],
+		Range: newRange(blockRange(block)),
+	}
 
-	body, ok := file.Body.(*hclsyntax.Body)
-	if !ok {
-		return resources
+	content, _, diags := block.Body.PartialContent(resourceSchema)
+
+	// Keep the count expression itself, which is rarely a literal
+	if countAttr, exists := content.Attributes["count"]; exists {
+		resource.Count = expressionSource(countAttr.Expr, file)
 	}
 
-	for _, block := range body.Blocks {
-		if block.Type != "resource" {
-			continue
-		}
+	if forEachAttr, exists := content.Attributes["for_each"]; exists {
+		resource.ForEach = expressionSource(forEachAttr.Expr, file)
+	}
 
-		if len(block.Labels) < 2 {
-			continue
-		}
+	if providerAttr, exists := content.Attributes["provider"]; exists {
+		resource.Provider = expressionSource(providerAttr.Expr, file)
+	}
 
-		resource := Resource{
-			Type: block.Labels[0],
-			Name: block.Labels[1],
-		}
+	if dependsAttr, exists := content.Attributes["depends_on"]; exists {
+		resource.DependsOn = expressionList(dependsAttr.Expr, file)
+	}
 
-		// Check if resource uses count
-		if countAttr, exists := block.Body.Attributes["count"]; exists {
-			// Try to extract count value
-			if val, diags := countAttr.Expr.Value(nil); !diags.HasErrors() {
-				if val.Type().IsPrimitiveType() {
-					resource.Count = 1 // Mark as using count
-				}
-			} else {
-				resource.Count = 1 // Count expression exists
-			}
-		}
+	for _, lifecycleBlock := range content.Blocks.OfType("lifecycle") {
+		lifecycle, lifecycleDiags := extractLifecycle(lifecycleBlock, file)
+		diags = append(diags, lifecycleDiags...)
+		resource.Lifecycle = lifecycle
+	}
+
+	return resource, diags
+}
+
+// extractLifecycle extracts the settings of a lifecycle block
+func extractLifecycle(block *hcl.Block, file *hcl.File) (*Lifecycle, hcl.Diagnostics) {
+	lifecycle := &Lifecycle{}
+	content, _, diags := block.Body.PartialContent(lifecycleSchema)
 
-		resources = append(resources, resource)
+	if attr, exists := content.Attributes["create_before_destroy"]; exists {
+		lifecycle.CreateBeforeDestroy, _ = literalBool(attr.Expr)
 	}
 
-	return resources
+	if attr, exists := content.Attributes["prevent_destroy"]; exists {
+		lifecycle.PreventDestroy, _ = literalBool(attr.Expr)
+	}
+
+	if attr, exists := content.Attributes["ignore_changes"]; exists {
+		lifecycle.IgnoreChanges = expressionList(attr.Expr, file)
+	}
+
+	if attr, exists := content.Attributes["replace_triggered_by"]; exists {
+		lifecycle.ReplaceTriggeredBy = expressionList(attr.Expr, file)
+	}
+
+	return lifecycle, diags
 }
 
-// extractModules extracts module blocks from an HCL file
-func (p *Parser) extractModules(file *hcl.File) []Module {
-	modules := []Module{}
+// extractLocals extracts the named values of a locals block
+func (p *Parser) extractLocals(block *hcl.Block, file *hcl.File) ([]Local, hcl.Diagnostics) {
+	attrs, diags := block.Body.JustAttributes()
+
+	locals := make([]Local, 0, len(attrs))
+	for _, attr := range sortedAttributes(attrs) {
+		locals = append(locals, Local{
+			Name:       attr.Name,
+			Expression: expressionSource(attr.Expr, file),
+			Range:      newRange(attr.Range),
+		})
+	}
+	return locals, diags
+}
 
-	body, ok := file.Body.(*hclsyntax.Body)
-	if !ok {
-		return modules
+// expressionList returns the source text of each element of a list
+// expression such as depends_on, or of the whole expression when it is not
+// a list, such as ignore_changes = all
+func expressionList(expr hcl.Expression, file *hcl.File) []string {
+	elems, diags := hcl.ExprList(expr)
+	if diags.HasErrors() {
+		return []string{expressionSource(expr, file)}
 	}
 
-	for _, block := range body.Blocks {
-		if block.Type != "module" {
-			continue
-		}
+	sources := make([]string, len(elems))
+	for i, elem := range elems {
+		sources[i] = expressionSource(elem, file)
+	}
+	return sources
+}
 
-		if len(block.Labels) == 0 {
-			continue
-		}
+// extractModule extracts a module block
+func (p *Parser) extractModule(block *hcl.Block, file *hcl.File) (Module, hcl.Diagnostics) {
+	module := Module{
+		Name:  block.Labels[0],
+		Range: newRange(blockRange(block)),
+	}
+
+	content, _, diags := block.Body.PartialContent(moduleSchema)
 
-		module := Module{
-			Name: block.Labels[0],
+	if sourceAttr, exists := content.Attributes["source"]; exists {
+		if val, ok := literalString(sourceAttr.Expr); ok {
+			module.Source = val
 		}
+	}
 
-		if sourceAttr, exists := block.Body.Attributes["source"]; exists {
-			if val, diags := sourceAttr.Expr.Value(nil); !diags.HasErrors() {
-				module.Source = val.AsString()
-			}
+	if versionAttr, exists := content.Attributes["version"]; exists {
+		if val, ok := literalString(versionAttr.Expr); ok {
+			module.Version = val
 		}
+	}
+
+	if countAttr, exists := content.Attributes["count"]; exists {
+		module.Count = expressionSource(countAttr.Expr, file)
+	}
 
-		if versionAttr, exists := block.Body.Attributes["version"]; exists {
-			if val, diags := versionAttr.Expr.Value(nil); !diags.HasErrors() {
-				module.Version = val.AsString()
+	if forEachAttr, exists := content.Attributes["for_each"]; exists {
+		module.ForEach = expressionSource(forEachAttr.Expr, file)
+	}
+
+	if dependsAttr, exists := content.Attributes["depends_on"]; exists {
+		module.DependsOn = expressionList(dependsAttr.Expr, file)
+	}
+
+	if providersAttr, exists := content.Attributes["providers"]; exists {
+		pairs, mapDiags := hcl.ExprMap(providersAttr.Expr)
+		diags = append(diags, mapDiags...)
+		if len(pairs) > 0 {
+			module.Providers = make(map[string]string, len(pairs))
+		}
+		for _, pair := range pairs {
+			// Keys are provider names, optionally with an alias
+			key, ok := literalString(pair.Key)
+			if !ok {
+				key = expressionSource(pair.Key, file)
 			}
+			module.Providers[key] = expressionSource(pair.Value, file)
 		}
+	}
 
-		modules = append(modules, module)
+	// Every other attribute sets an input variable of the module. Nested
+	// blocks are not valid here, so their diagnostics are dropped
+	attrs, _ := block.Body.JustAttributes()
+	for _, attr := range sortedAttributes(attrs) {
+		if moduleMetaArguments[attr.Name] {
+			continue
+		}
+		argument := ModuleArgument{
+			Name:       attr.Name,
+			Expression: expressionSource(attr.Expr, file),
+			Range:      newRange(attr.Range),
+		}
+		if val, valDiags := attr.Expr.Value(nil); !valDiags.HasErrors() && val.IsWhollyKnown() {
+			argument.Value = val
+		}
+		module.Arguments = append(module.Arguments, argument)
 	}
 
-	return modules
+	return module, diags
 }
 
 // extractTerraformBlock extracts terraform version and provider requirements
-func (p *Parser) extractTerraformBlock(file *hcl.File) (string, []Provider) {
+func (p *Parser) extractTerraformBlock(block *hcl.Block) (string, []Provider, hcl.Diagnostics) {
 	var terraformVersion string
 	providers := []Provider{}
 
-	body, ok := file.Body.(*hclsyntax.Body)
-	if !ok {
-		return terraformVersion, providers
-	}
+	content, _, diags := block.Body.PartialContent(terraformSchema)
 
-	for _, block := range body.Blocks {
-		if block.Type != "terraform" {
-			continue
+	// Extract required_version
+	if versionAttr, exists := content.Attributes["required_version"]; exists {
+		if val, ok := literalString(versionAttr.Expr); ok {
+			terraformVersion = val
 		}
+	}
 
-		// Extract required_version
-		if versionAttr, exists := block.Body.Attributes["required_version"]; exists {
-			if val, diags := versionAttr.Expr.Value(nil); !diags.HasErrors() {
-				terraformVersion = val.AsString()
-			}
-		}
+	// Extract required_providers block
+	for _, nestedBlock := range content.Blocks.OfType("required_providers") {
+		attrs, attrDiags := nestedBlock.Body.JustAttributes()
+		diags = append(diags, attrDiags...)
 
-		// Extract required_providers block
-		for _, nestedBlock := range block.Body.Blocks {
-			if nestedBlock.Type != "required_providers" {
-				continue
+		// Each attribute in required_providers is a provider
+		for _, attr := range sortedAttributes(attrs) {
+			provider := Provider{
+				Name:  attr.Name,
+				Range: newRange(attr.Range),
 			}
 
-			// Each attribute in required_providers is a provider
-			for name, attr := range nestedBlock.Body.Attributes {
-				provider := Provider{
-					Name: name,
-				}
-
-				// Parse provider configuration (object with source and version)
-				if val, diags := attr.Expr.Value(nil); !diags.HasErrors() {
-					if val.Type().IsObjectType() {
-						// Extract source
-						if sourceVal := val.GetAttr("source"); !sourceVal.IsNull() {
-							provider.Source = sourceVal.AsString()
+			// Parse provider configuration (object with source and version)
+			if val, valDiags := attr.Expr.Value(nil); !valDiags.HasErrors() && val.IsWhollyKnown() && !val.IsNull() {
+				if val.Type().IsObjectType() || val.Type().IsMapType() {
+					for name, attrVal := range val.AsValueMap() {
+						if attrVal.IsNull() || !attrVal.Type().Equals(cty.String) {
+							continue
 						}
-						// Extract version
-						if versionVal := val.GetAttr("version"); !versionVal.IsNull() {
-							provider.Version = versionVal.AsString()
+						switch name {
+						case "source":
+							provider.Source = attrVal.AsString()
+						case "version":
+							provider.Version = attrVal.AsString()
 						}
 					}
 				}
-
-				providers = append(providers, provider)
 			}
+
+			providers = append(providers, provider)
 		}
 	}
 
-	return terraformVersion, providers
+	return terraformVersion, providers, diags
+}
+
+// sortedAttributes returns attributes in declaration order
+func sortedAttributes(attrs hcl.Attributes) []*hcl.Attribute {
+	sorted := make([]*hcl.Attribute, 0, len(attrs))
+	for _, attr := range attrs {
+		sorted = append(sorted, attr)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Range.Start.Byte < sorted[j].Range.Start.Byte
+	})
+	return sorted
 }
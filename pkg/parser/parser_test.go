@@ -1,12 +1,14 @@
 package parser
 
 import (
+	"encoding/json"
 	"io"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestParseFiles(t *testing.T) {
@@ -232,6 +234,38 @@ variable "required_non_null" {
 		assert.False(t, v.Nullable)
 	})
 
+	t.Run("null default requires a nullable variable", func(t *testing.T) {
+		parser := NewParser()
+		tfContent := `
+variable "optional" {
+  type    = string
+  default = null
+}
+
+variable "invalid" {
+  type     = string
+  nullable = false
+  default  = null
+}
+`
+		files := map[string]io.Reader{
+			"variables.tf": strings.NewReader(tfContent),
+		}
+
+		result, err := parser.ParseFiles(files)
+		require.Error(t, err)
+		require.Len(t, result.Variables, 2)
+		assert.True(t, result.Variables[0].Nullable)
+		assert.False(t, result.Variables[0].Required)
+
+		require.Len(t, result.Diagnostics, 1)
+		diag := result.Diagnostics[0]
+		assert.Equal(t, "Invalid default value for variable", diag.Summary)
+		assert.Equal(t, "A null default value is not valid when nullable=false.", diag.Detail)
+		require.NotNil(t, diag.Subject)
+		assert.Equal(t, 10, diag.Subject.Start.Line)
+	})
+
 	t.Run("parse nullable default true when not specified", func(t *testing.T) {
 		parser := NewParser()
 		tfContent := `
@@ -802,3 +836,473 @@ variable "config_map" {
 		assert.Contains(t, v.Type, "map")
 	})
 }
+
+func TestParseFiles_Ordering(t *testing.T) {
+	newFiles := func() map[string]io.Reader {
+		return map[string]io.Reader{
+			"b.tf": strings.NewReader(`
+variable "zulu" {}
+variable "alpha" {}
+`),
+			"a.tf": strings.NewReader(`
+variable "mike" {}
+`),
+			"versions.tf": strings.NewReader(`
+terraform {
+  required_providers {
+    random = { source = "hashicorp/random", version = ">= 3.0" }
+    aws    = { source = "hashicorp/aws", version = ">= 5.0" }
+    null   = { source = "hashicorp/null", version = ">= 3.0" }
+  }
+}
+`),
+		}
+	}
+
+	t.Run("variables follow file name then declaration order", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			result, err := NewParser().ParseFiles(newFiles())
+			require.NoError(t, err)
+
+			names := []string{}
+			for _, v := range result.Variables {
+				names = append(names, v.Name)
+			}
+			require.Equal(t, []string{"mike", "zulu", "alpha"}, names)
+		}
+	})
+
+	t.Run("providers follow declaration order", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			result, err := NewParser().ParseFiles(newFiles())
+			require.NoError(t, err)
+
+			names := []string{}
+			for _, p := range result.Providers {
+				names = append(names, p.Name)
+			}
+			require.Equal(t, []string{"random", "aws", "null"}, names)
+		}
+	})
+}
+
+func TestParseFiles_SourceRanges(t *testing.T) {
+	tfContent := `variable "region" {
+  type = string
+}
+
+output "arn" {
+  value = aws_s3_bucket.this.arn
+}
+
+resource "aws_s3_bucket" "this" {
+  bucket = var.region
+}
+
+module "vpc" {
+  source = "./vpc"
+}
+
+terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = ">= 5.0"
+    }
+  }
+}
+`
+	result, err := NewParser().ParseFiles(map[string]io.Reader{
+		"main.tf": strings.NewReader(tfContent),
+	})
+	require.NoError(t, err)
+
+	t.Run("variable range covers the block", func(t *testing.T) {
+		require.Len(t, result.Variables, 1)
+		rng := result.Variables[0].Range
+		assert.Equal(t, "main.tf", rng.Filename)
+		assert.Equal(t, 1, rng.Start.Line)
+		assert.Equal(t, 1, rng.Start.Column)
+		assert.Equal(t, 3, rng.End.Line)
+		assert.Equal(t, "main.tf:1,1-3,2", rng.String())
+	})
+
+	t.Run("other blocks carry ranges", func(t *testing.T) {
+		require.Len(t, result.Outputs, 1)
+		assert.Equal(t, 5, result.Outputs[0].Range.Start.Line)
+
+		require.Len(t, result.Resources, 1)
+		assert.Equal(t, 9, result.Resources[0].Range.Start.Line)
+		assert.Equal(t, 11, result.Resources[0].Range.End.Line)
+
+		require.Len(t, result.Modules, 1)
+		assert.Equal(t, 13, result.Modules[0].Range.Start.Line)
+
+		require.Len(t, result.Providers, 1)
+		assert.Equal(t, "main.tf", result.Providers[0].Range.Filename)
+		assert.Equal(t, 19, result.Providers[0].Range.Start.Line)
+		assert.Equal(t, 22, result.Providers[0].Range.End.Line)
+	})
+}
+
+func TestParseFiles_JSONSyntax(t *testing.T) {
+	jsonContent := `{
+  "terraform": {
+    "required_version": ">= 1.5",
+    "required_providers": {
+      "aws": { "source": "hashicorp/aws", "version": ">= 5.0" }
+    }
+  },
+  "variable": {
+    "instance_type": {
+      "type": "string",
+      "description": "EC2 instance type",
+      "default": "t3.micro",
+      "validation": {
+        "condition": "${contains([\"t3.micro\", \"t3.small\"], var.instance_type)}",
+        "error_message": "Unsupported instance type."
+      }
+    },
+    "services": {
+      "type": "list(object({ name = string, port = optional(number, 80) }))",
+      "default": [{ "name": "web" }]
+    },
+    "password": {
+      "type": "string",
+      "sensitive": true,
+      "nullable": false
+    },
+    "anything": {}
+  },
+  "output": {
+    "arn": {
+      "description": "Bucket ARN",
+      "value": "${aws_s3_bucket.this.arn}"
+    }
+  },
+  "resource": {
+    "aws_s3_bucket": {
+      "this": { "bucket": "example", "count": 1 }
+    }
+  },
+  "module": {
+    "vpc": { "source": "terraform-aws-modules/vpc/aws", "version": "5.0.0" }
+  }
+}`
+
+	result, err := NewParser().ParseFiles(map[string]io.Reader{
+		"main.tf.json": strings.NewReader(jsonContent),
+	})
+	require.NoError(t, err)
+
+	variables := map[string]Variable{}
+	for _, v := range result.Variables {
+		variables[v.Name] = v
+	}
+
+	t.Run("variables keep declaration order", func(t *testing.T) {
+		names := []string{}
+		for _, v := range result.Variables {
+			names = append(names, v.Name)
+		}
+		assert.Equal(t, []string{"instance_type", "services", "password", "anything"}, names)
+	})
+
+	t.Run("primitive variable", func(t *testing.T) {
+		v := variables["instance_type"]
+		assert.Equal(t, "string", v.Type)
+		require.NotNil(t, v.TypeSpec)
+		assert.Equal(t, TypeString, v.TypeSpec.Kind)
+		assert.Equal(t, "EC2 instance type", v.Description)
+		assert.Equal(t, "t3.micro", v.Default)
+		assert.False(t, v.Required)
+
+		require.Len(t, v.Validations, 1)
+		assert.Equal(t, `contains(["t3.micro", "t3.small"], var.instance_type)`, v.Validations[0].Condition)
+		assert.Equal(t, "Unsupported instance type.", v.Validations[0].ErrorMessage)
+	})
+
+	t.Run("type expressions written as strings", func(t *testing.T) {
+		v := variables["services"]
+		assert.Equal(t, "list(object({ name = string, port = optional(number, 80) }))", v.Type)
+		require.NotNil(t, v.TypeSpec)
+		assert.Equal(t, TypeList, v.TypeSpec.Kind)
+		assert.True(t, v.TypeSpec.Element.Attribute("port").Optional)
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"name": "web", "port": json.Number("80")},
+		}, v.Default)
+	})
+
+	t.Run("flags and untyped variables", func(t *testing.T) {
+		password := variables["password"]
+		assert.True(t, password.Sensitive)
+		assert.False(t, password.Nullable)
+		assert.True(t, password.Required)
+
+		anything := variables["anything"]
+		assert.Empty(t, anything.Type)
+		assert.Nil(t, anything.TypeSpec)
+		assert.True(t, anything.Nullable)
+	})
+
+	t.Run("other blocks", func(t *testing.T) {
+		assert.Equal(t, ">= 1.5", result.TerraformVersion)
+
+		require.Len(t, result.Providers, 1)
+		assert.Equal(t, "aws", result.Providers[0].Name)
+		assert.Equal(t, "hashicorp/aws", result.Providers[0].Source)
+		assert.Equal(t, ">= 5.0", result.Providers[0].Version)
+
+		require.Len(t, result.Outputs, 1)
+		assert.Equal(t, "Bucket ARN", result.Outputs[0].Description)
+		assert.Equal(t, "aws_s3_bucket.this.arn", result.Outputs[0].Value)
+
+		require.Len(t, result.Resources, 1)
+		assert.Equal(t, "aws_s3_bucket", result.Resources[0].Type)
+		assert.Equal(t, "this", result.Resources[0].Name)
+		assert.Equal(t, "1", result.Resources[0].Count)
+
+		require.Len(t, result.Modules, 1)
+		assert.Equal(t, "terraform-aws-modules/vpc/aws", result.Modules[0].Source)
+		assert.Equal(t, "5.0.0", result.Modules[0].Version)
+	})
+
+	t.Run("ranges point into the JSON file", func(t *testing.T) {
+		rng := variables["instance_type"].Range
+		assert.Equal(t, "main.tf.json", rng.Filename)
+		assert.Equal(t, 9, rng.Start.Line)
+		assert.Equal(t, 17, rng.End.Line)
+	})
+
+	t.Run("invalid type string is reported in the JSON file", func(t *testing.T) {
+		result, err := NewParser().ParseFiles(map[string]io.Reader{
+			"main.tf.json": strings.NewReader(`{"variable": {"x": {"type": "lists(string)"}}}`),
+		})
+		require.Error(t, err)
+		require.Len(t, result.Diagnostics, 1)
+		diag := result.Diagnostics[0]
+		assert.Equal(t, "Invalid type specification", diag.Summary)
+		require.NotNil(t, diag.Subject)
+		assert.Equal(t, 30, diag.Subject.Start.Column)
+		require.NotNil(t, diag.Snippet)
+		assert.Equal(t, "lists", diag.Snippet.Code[diag.Snippet.HighlightStartOffset:diag.Snippet.HighlightEndOffset])
+	})
+
+	t.Run("non-string type is reported", func(t *testing.T) {
+		_, err := NewParser().ParseFiles(map[string]io.Reader{
+			"main.tf.json": strings.NewReader(`{"variable": {"x": {"type": ["string"]}}}`),
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Invalid type specification")
+	})
+
+	t.Run("syntax errors are diagnostics", func(t *testing.T) {
+		result, err := NewParser().ParseFiles(map[string]io.Reader{
+			"main.tf.json": strings.NewReader(`{"variable": {`),
+		})
+		require.Error(t, err)
+		assert.True(t, result.Diagnostics.HasErrors())
+	})
+}
+
+func TestParseFiles_ModuleArguments(t *testing.T) {
+	result, err := NewParser().ParseFiles(map[string]io.Reader{
+		"main.tf": strings.NewReader(`
+module "network" {
+  source     = "./modules/network"
+  count      = 2
+  depends_on = [aws_s3_bucket.logs]
+
+  cidr  = "10.0.0.0/16"
+  zones = ["a", "b"]
+  name  = "${var.prefix}-network"
+  tags  = merge(var.tags, { team = "core" })
+}
+`),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, result.Modules, 1)
+	args := result.Modules[0].Arguments
+	require.Len(t, args, 4)
+
+	assert.Equal(t, "cidr", args[0].Name)
+	assert.Equal(t, `"10.0.0.0/16"`, args[0].Expression)
+	assert.True(t, args[0].IsLiteral())
+	assert.Equal(t, cty.StringVal("10.0.0.0/16"), args[0].Value)
+	assert.Equal(t, 7, args[0].Range.Start.Line)
+
+	assert.Equal(t, "zones", args[1].Name)
+	assert.True(t, args[1].IsLiteral())
+
+	assert.Equal(t, "name", args[2].Name)
+	assert.False(t, args[2].IsLiteral())
+	assert.Equal(t, `"${var.prefix}-network"`, args[2].Expression)
+
+	assert.Equal(t, "tags", args[3].Name)
+	assert.False(t, args[3].IsLiteral())
+}
+
+func TestParseFiles_MetaArguments(t *testing.T) {
+	result, err := NewParser().ParseFiles(map[string]io.Reader{
+		"main.tf": strings.NewReader(`
+locals {
+  prefix = "${var.name}-${var.environment}"
+  zones  = ["a", "b"]
+}
+
+data "aws_caller_identity" "current" {}
+
+resource "aws_s3_bucket" "logs" {
+  count    = var.enabled ? 1 : 0
+  provider = aws.west
+
+  lifecycle {
+    prevent_destroy = true
+    ignore_changes  = [tags, tags_all]
+  }
+}
+
+resource "aws_s3_bucket" "data" {
+  for_each   = toset(local.zones)
+  depends_on = [aws_s3_bucket.logs, data.aws_caller_identity.current]
+
+  lifecycle {
+    create_before_destroy = true
+    ignore_changes        = all
+    replace_triggered_by  = [aws_s3_bucket.logs[0].id]
+  }
+}
+
+resource "aws_s3_bucket" "none" {
+  count = 0
+}
+
+module "network" {
+  source   = "./modules/network"
+  for_each = var.regions
+  providers = {
+    aws      = aws.west
+    aws.peer = aws.east
+  }
+}
+
+output "bucket" {
+  value      = aws_s3_bucket.logs[0].id
+  depends_on = [aws_s3_bucket.data]
+}
+`),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, result.Locals, 2)
+	assert.Equal(t, "prefix", result.Locals[0].Name)
+	assert.Equal(t, `"${var.name}-${var.environment}"`, result.Locals[0].Expression)
+	assert.Equal(t, 3, result.Locals[0].Range.Start.Line)
+	assert.Equal(t, "zones", result.Locals[1].Name)
+	assert.Equal(t, `["a", "b"]`, result.Locals[1].Expression)
+
+	require.Len(t, result.DataSources, 1)
+	assert.Equal(t, "aws_caller_identity", result.DataSources[0].Type)
+	assert.Equal(t, "current", result.DataSources[0].Name)
+
+	require.Len(t, result.Resources, 3)
+	logs := result.Resources[0]
+	assert.Equal(t, "var.enabled ? 1 : 0", logs.Count)
+	assert.Equal(t, "aws.west", logs.Provider)
+	require.NotNil(t, logs.Lifecycle)
+	assert.True(t, logs.Lifecycle.PreventDestroy)
+	assert.Equal(t, []string{"tags", "tags_all"}, logs.Lifecycle.IgnoreChanges)
+
+	data := result.Resources[1]
+	assert.Empty(t, data.Count)
+	assert.Equal(t, "toset(local.zones)", data.ForEach)
+	assert.Equal(t, []string{"aws_s3_bucket.logs", "data.aws_caller_identity.current"}, data.DependsOn)
+	require.NotNil(t, data.Lifecycle)
+	assert.True(t, data.Lifecycle.CreateBeforeDestroy)
+	assert.Equal(t, []string{"all"}, data.Lifecycle.IgnoreChanges)
+	assert.Equal(t, []string{"aws_s3_bucket.logs[0].id"}, data.Lifecycle.ReplaceTriggeredBy)
+
+	// A count of zero is kept rather than read as a single instance
+	assert.Equal(t, "0", result.Resources[2].Count)
+	assert.Nil(t, result.Resources[2].Lifecycle)
+
+	require.Len(t, result.Modules, 1)
+	assert.Equal(t, "var.regions", result.Modules[0].ForEach)
+	assert.Equal(t, map[string]string{"aws": "aws.west", "aws.peer": "aws.east"}, result.Modules[0].Providers)
+	assert.Empty(t, result.Modules[0].Arguments)
+
+	require.Len(t, result.Outputs, 1)
+	assert.Equal(t, []string{"aws_s3_bucket.data"}, result.Outputs[0].DependsOn)
+}
+
+func TestParseFiles_MetaArgumentsJSON(t *testing.T) {
+	result, err := NewParser().ParseFiles(map[string]io.Reader{
+		"main.tf.json": strings.NewReader(`{
+  "locals": { "prefix": "${var.name}-app" },
+  "data": {
+    "aws_region": { "current": {} }
+  },
+  "resource": {
+    "aws_s3_bucket": {
+      "logs": {
+        "count": "${var.enabled ? 1 : 0}",
+        "provider": "aws.west",
+        "depends_on": ["data.aws_region.current"],
+        "lifecycle": { "prevent_destroy": true, "ignore_changes": "all" }
+      }
+    }
+  },
+  "module": {
+    "network": {
+      "source": "./modules/network",
+      "count": 2,
+      "providers": { "aws": "aws.west" }
+    }
+  }
+}`),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, result.Locals, 1)
+	assert.Equal(t, "${var.name}-app", result.Locals[0].Expression)
+
+	require.Len(t, result.DataSources, 1)
+	assert.Equal(t, "aws_region", result.DataSources[0].Type)
+
+	require.Len(t, result.Resources, 1)
+	logs := result.Resources[0]
+	assert.Equal(t, "var.enabled ? 1 : 0", logs.Count)
+	assert.Equal(t, "aws.west", logs.Provider)
+	assert.Equal(t, []string{"data.aws_region.current"}, logs.DependsOn)
+	require.NotNil(t, logs.Lifecycle)
+	assert.True(t, logs.Lifecycle.PreventDestroy)
+	assert.Equal(t, []string{"all"}, logs.Lifecycle.IgnoreChanges)
+
+	require.Len(t, result.Modules, 1)
+	assert.Equal(t, "2", result.Modules[0].Count)
+	assert.Equal(t, map[string]string{"aws": "aws.west"}, result.Modules[0].Providers)
+}
+
+func TestParseFiles_OtherJSONFiles(t *testing.T) {
+	result, err := NewParser().ParseFiles(map[string]io.Reader{
+		"main.tf.json": strings.NewReader(`{"variable": {"name": {"type": "string"}}}`),
+		"package.json": strings.NewReader(`{"name": "x", "variable": 1}`),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, result.Variables, 1)
+	assert.Equal(t, "name", result.Variables[0].Name)
+
+	require.Len(t, result.Diagnostics, 1)
+	assert.Equal(t, SeverityWarning, result.Diagnostics[0].Severity)
+	assert.Contains(t, result.Diagnostics[0].Detail, `"package.json" was skipped`)
+}
+
+func TestIsJSONFilename(t *testing.T) {
+	assert.True(t, IsJSONFilename("main.tf.json"))
+	assert.True(t, IsJSONFilename("modules/network/variables.tf.json"))
+	assert.False(t, IsJSONFilename("main.tf"))
+	assert.False(t, IsJSONFilename("package.json"))
+	assert.False(t, IsJSONFilename("schema.json"))
+}
@@ -0,0 +1,364 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// TypeKind identifies the kind of a Terraform type constraint
+type TypeKind string
+
+// Supported Terraform type constraint kinds
+const (
+	TypeAny    TypeKind = "any"
+	TypeString TypeKind = "string"
+	TypeNumber TypeKind = "number"
+	TypeBool   TypeKind = "bool"
+	TypeList   TypeKind = "list"
+	TypeSet    TypeKind = "set"
+	TypeMap    TypeKind = "map"
+	TypeObject TypeKind = "object"
+	TypeTuple  TypeKind = "tuple"
+)
+
+// TypeSpec is the decoded form of a Terraform type constraint such as
+// list(object({ name = string, port = optional(number, 80) }))
+type TypeSpec struct {
+	Kind TypeKind `json:"kind"`
+
+	// Element is the element type of list, set and map types
+	Element *TypeSpec `json:"element,omitempty"`
+
+	// Elements are the positional element types of tuple types
+	Elements []*TypeSpec `json:"elements,omitempty"`
+
+	// Attributes are the attributes of object types, in declaration order
+	Attributes []ObjectAttribute `json:"attributes,omitempty"`
+}
+
+// ObjectAttribute represents a single attribute of an object type constraint
+type ObjectAttribute struct {
+	Name     string      `json:"name"`
+	Type     *TypeSpec   `json:"type"`
+	Optional bool        `json:"optional,omitempty"`
+	Default  interface{} `json:"default,omitempty"`
+}
+
+// IsPrimitive reports whether the type is string, number or bool
+func (t *TypeSpec) IsPrimitive() bool {
+	switch t.Kind {
+	case TypeString, TypeNumber, TypeBool:
+		return true
+	}
+	return false
+}
+
+// IsCollection reports whether the type is a list, set or map
+func (t *TypeSpec) IsCollection() bool {
+	switch t.Kind {
+	case TypeList, TypeSet, TypeMap:
+		return true
+	}
+	return false
+}
+
+// Attribute returns the object attribute with the given name, or nil
+func (t *TypeSpec) Attribute(name string) *ObjectAttribute {
+	for i := range t.Attributes {
+		if t.Attributes[i].Name == name {
+			return &t.Attributes[i]
+		}
+	}
+	return nil
+}
+
+// String renders the type constraint in canonical Terraform syntax
+func (t *TypeSpec) String() string {
+	if t == nil {
+		return ""
+	}
+
+	switch t.Kind {
+	case TypeList, TypeSet, TypeMap:
+		return fmt.Sprintf("%s(%s)", t.Kind, t.Element.String())
+	case TypeTuple:
+		elems := make([]string, len(t.Elements))
+		for i, elem := range t.Elements {
+			elems[i] = elem.String()
+		}
+		return fmt.Sprintf("tuple([%s])", strings.Join(elems, ", "))
+	case TypeObject:
+		attrs := make([]string, len(t.Attributes))
+		for i, attr := range t.Attributes {
+			attrs[i] = fmt.Sprintf("%s = %s", attr.Name, attr.typeString())
+		}
+		return fmt.Sprintf("object({%s})", strings.Join(attrs, ", "))
+	default:
+		return string(t.Kind)
+	}
+}
+
+// CtyType returns the cty type equivalent to the type constraint, with
+// optional() attributes marked as optional
+func (t *TypeSpec) CtyType() cty.Type {
+	switch t.Kind {
+	case TypeString:
+		return cty.String
+	case TypeNumber:
+		return cty.Number
+	case TypeBool:
+		return cty.Bool
+	case TypeList:
+		return cty.List(t.Element.CtyType())
+	case TypeSet:
+		return cty.Set(t.Element.CtyType())
+	case TypeMap:
+		return cty.Map(t.Element.CtyType())
+	case TypeTuple:
+		elems := make([]cty.Type, len(t.Elements))
+		for i, elem := range t.Elements {
+			elems[i] = elem.CtyType()
+		}
+		return cty.Tuple(elems)
+	case TypeObject:
+		attrs := make(map[string]cty.Type, len(t.Attributes))
+		var optional []string
+		for _, attr := range t.Attributes {
+			attrs[attr.Name] = attr.Type.CtyType()
+			if attr.Optional {
+				optional = append(optional, attr.Name)
+			}
+		}
+		return cty.ObjectWithOptionalAttrs(attrs, optional)
+	default:
+		return cty.DynamicPseudoType
+	}
+}
+
+// typeString renders the attribute type, including any optional() modifier
+func (a ObjectAttribute) typeString() string {
+	if !a.Optional {
+		return a.Type.String()
+	}
+	if a.Default == nil {
+		return fmt.Sprintf("optional(%s)", a.Type.String())
+	}
+	// JSON literals are valid HCL expressions, so defaults can be rendered as JSON
+	def, err := json.Marshal(a.Default)
+	if err != nil {
+		return fmt.Sprintf("optional(%s)", a.Type.String())
+	}
+	return fmt.Sprintf("optional(%s, %s)", a.Type.String(), def)
+}
+
+// ParseTypeString decodes a type constraint written in Terraform syntax
+func ParseTypeString(src string) (*TypeSpec, error) {
+	expr, diags := hclsyntax.ParseExpression([]byte(src), "<type>", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	p := NewParser()
+	spec, diags := p.parseTypeSpec(expr)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return spec, nil
+}
+
+// parseTypeSpec decodes a type constraint expression into a TypeSpec
+func (p *Parser) parseTypeSpec(expr hcl.Expression) (*TypeSpec, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	if keyword := hcl.ExprAsKeyword(expr); keyword != "" {
+		switch TypeKind(keyword) {
+		case TypeAny, TypeString, TypeNumber, TypeBool:
+			return &TypeSpec{Kind: TypeKind(keyword)}, nil
+		case TypeList, TypeSet, TypeMap, TypeObject, TypeTuple:
+			return nil, diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid type specification",
+				Detail:   fmt.Sprintf("The %s type constructor requires one argument specifying the element type.", keyword),
+				Subject:  expr.Range().Ptr(),
+			})
+		default:
+			return nil, diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid type specification",
+				Detail:   fmt.Sprintf("The keyword %q is not a valid type specification.", keyword),
+				Subject:  expr.Range().Ptr(),
+			})
+		}
+	}
+
+	call, callDiags := hcl.ExprCall(expr)
+	if callDiags.HasErrors() {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid type specification",
+			Detail:   "A type specification is either a primitive type keyword (bool, number, string) or a complex type constructor call, like list(string).",
+			Subject:  expr.Range().Ptr(),
+		})
+	}
+
+	switch call.Name {
+	case "optional":
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid type specification",
+			Detail:   "Keyword \"optional\" is valid only as a modifier for object type attributes.",
+			Subject:  call.NameRange.Ptr(),
+		})
+	case string(TypeList), string(TypeSet), string(TypeMap):
+		if len(call.Arguments) != 1 {
+			return nil, diags.Append(wrongArgsDiag(call, "the element type"))
+		}
+		elem, elemDiags := p.parseTypeSpec(call.Arguments[0])
+		diags = append(diags, elemDiags...)
+		if elem == nil {
+			return nil, diags
+		}
+		return &TypeSpec{Kind: TypeKind(call.Name), Element: elem}, diags
+	case string(TypeTuple):
+		if len(call.Arguments) != 1 {
+			return nil, diags.Append(wrongArgsDiag(call, "a list of element types"))
+		}
+		exprs, listDiags := hcl.ExprList(call.Arguments[0])
+		if listDiags.HasErrors() {
+			return nil, diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid type specification",
+				Detail:   "Tuple type constructor requires a list of element types.",
+				Subject:  call.Arguments[0].Range().Ptr(),
+			})
+		}
+		spec := &TypeSpec{Kind: TypeTuple, Elements: make([]*TypeSpec, 0, len(exprs))}
+		for _, elemExpr := range exprs {
+			elem, elemDiags := p.parseTypeSpec(elemExpr)
+			diags = append(diags, elemDiags...)
+			if elem == nil {
+				return nil, diags
+			}
+			spec.Elements = append(spec.Elements, elem)
+		}
+		return spec, diags
+	case string(TypeObject):
+		if len(call.Arguments) != 1 {
+			return nil, diags.Append(wrongArgsDiag(call, "an object describing the attribute types"))
+		}
+		pairs, mapDiags := hcl.ExprMap(call.Arguments[0])
+		if mapDiags.HasErrors() {
+			return nil, diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid type specification",
+				Detail:   "Object type constructor requires a map whose keys are attribute names and whose values are the corresponding attribute types.",
+				Subject:  call.Arguments[0].Range().Ptr(),
+			})
+		}
+		spec := &TypeSpec{Kind: TypeObject, Attributes: make([]ObjectAttribute, 0, len(pairs))}
+		for _, pair := range pairs {
+			name := hcl.ExprAsKeyword(pair.Key)
+			if name == "" {
+				if val, valDiags := pair.Key.Value(nil); !valDiags.HasErrors() && val.Type() == cty.String && val.IsKnown() && !val.IsNull() {
+					name = val.AsString()
+				}
+			}
+			if name == "" {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid type specification",
+					Detail:   "Object constructor map keys must be attribute names.",
+					Subject:  pair.Key.Range().Ptr(),
+				})
+				continue
+			}
+
+			attr, attrDiags := p.parseObjectAttribute(name, pair.Value)
+			diags = append(diags, attrDiags...)
+			if attr.Type == nil {
+				continue
+			}
+			spec.Attributes = append(spec.Attributes, attr)
+		}
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		return spec, diags
+	default:
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid type specification",
+			Detail:   fmt.Sprintf("Keyword %q is not a valid type constructor.", call.Name),
+			Subject:  call.NameRange.Ptr(),
+		})
+	}
+}
+
+// parseObjectAttribute decodes an object attribute type, unwrapping any optional() modifier
+func (p *Parser) parseObjectAttribute(name string, expr hcl.Expression) (ObjectAttribute, hcl.Diagnostics) {
+	attr := ObjectAttribute{Name: name}
+
+	call, callDiags := hcl.ExprCall(expr)
+	if callDiags.HasErrors() || call.Name != "optional" {
+		spec, diags := p.parseTypeSpec(expr)
+		attr.Type = spec
+		return attr, diags
+	}
+
+	var diags hcl.Diagnostics
+	if len(call.Arguments) < 1 || len(call.Arguments) > 2 {
+		return attr, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid type specification",
+			Detail:   "Optional attribute modifier expects the attribute type and an optional default value.",
+			Subject:  call.ArgsRange.Ptr(),
+		})
+	}
+
+	spec, typeDiags := p.parseTypeSpec(call.Arguments[0])
+	diags = append(diags, typeDiags...)
+	attr.Type = spec
+	attr.Optional = true
+
+	if len(call.Arguments) == 2 {
+		val, valDiags := call.Arguments[1].Value(nil)
+		if valDiags.HasErrors() {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid default value for optional attribute",
+				Detail:   fmt.Sprintf("The default value for attribute %q must be a literal value.", name),
+				Subject:  call.Arguments[1].Range().Ptr(),
+			})
+		} else if spec != nil {
+			val, err := ConformValue(val, spec)
+			if err == nil {
+				attr.Default, err = ConvertCtyValue(val)
+			}
+			if err != nil {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid default value for optional attribute",
+					Detail:   fmt.Sprintf("The default value for attribute %q is not compatible with its type: %s.", name, err),
+					Subject:  call.Arguments[1].Range().Ptr(),
+				})
+			}
+		}
+	}
+
+	return attr, diags
+}
+
+// wrongArgsDiag builds the diagnostic for a type constructor called with the wrong number of arguments
+func wrongArgsDiag(call *hcl.StaticCall, expected string) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Invalid type specification",
+		Detail:   fmt.Sprintf("The %s type constructor requires one argument specifying %s.", call.Name, expected),
+		Subject:  call.ArgsRange.Ptr(),
+	}
+}
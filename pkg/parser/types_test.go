@@ -0,0 +1,185 @@
+package parser
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTypeString(t *testing.T) {
+	t.Run("primitive types", func(t *testing.T) {
+		for _, kind := range []TypeKind{TypeString, TypeNumber, TypeBool, TypeAny} {
+			spec, err := ParseTypeString(string(kind))
+			require.NoError(t, err)
+			assert.Equal(t, kind, spec.Kind)
+			assert.Nil(t, spec.Element)
+		}
+	})
+
+	t.Run("collection types", func(t *testing.T) {
+		for _, kind := range []TypeKind{TypeList, TypeSet, TypeMap} {
+			spec, err := ParseTypeString(string(kind) + "(number)")
+			require.NoError(t, err)
+			assert.Equal(t, kind, spec.Kind)
+			require.NotNil(t, spec.Element)
+			assert.Equal(t, TypeNumber, spec.Element.Kind)
+			assert.True(t, spec.IsCollection())
+		}
+	})
+
+	t.Run("tuple type", func(t *testing.T) {
+		spec, err := ParseTypeString("tuple([string, number, bool])")
+		require.NoError(t, err)
+		assert.Equal(t, TypeTuple, spec.Kind)
+		require.Len(t, spec.Elements, 3)
+		assert.Equal(t, TypeString, spec.Elements[0].Kind)
+		assert.Equal(t, TypeNumber, spec.Elements[1].Kind)
+		assert.Equal(t, TypeBool, spec.Elements[2].Kind)
+	})
+
+	t.Run("object attributes keep declaration order", func(t *testing.T) {
+		spec, err := ParseTypeString("object({zeta = string, alpha = number, mid = bool})")
+		require.NoError(t, err)
+		require.Len(t, spec.Attributes, 3)
+		assert.Equal(t, "zeta", spec.Attributes[0].Name)
+		assert.Equal(t, "alpha", spec.Attributes[1].Name)
+		assert.Equal(t, "mid", spec.Attributes[2].Name)
+	})
+
+	t.Run("optional attributes with and without defaults", func(t *testing.T) {
+		spec, err := ParseTypeString(`object({
+  name    = string
+  port    = optional(number, 80)
+  tags    = optional(map(string))
+})`)
+		require.NoError(t, err)
+
+		name := spec.Attribute("name")
+		require.NotNil(t, name)
+		assert.False(t, name.Optional)
+
+		port := spec.Attribute("port")
+		require.NotNil(t, port)
+		assert.True(t, port.Optional)
+		assert.Equal(t, TypeNumber, port.Type.Kind)
+		assert.NotNil(t, port.Default)
+
+		tags := spec.Attribute("tags")
+		require.NotNil(t, tags)
+		assert.True(t, tags.Optional)
+		assert.Nil(t, tags.Default)
+		assert.Equal(t, TypeMap, tags.Type.Kind)
+		assert.Equal(t, TypeString, tags.Type.Element.Kind)
+
+		assert.Nil(t, spec.Attribute("missing"))
+	})
+
+	t.Run("deeply nested types", func(t *testing.T) {
+		spec, err := ParseTypeString("list(object({ name = string, rules = optional(list(object({ port = number }))) }))")
+		require.NoError(t, err)
+		assert.Equal(t, TypeList, spec.Kind)
+
+		obj := spec.Element
+		require.Equal(t, TypeObject, obj.Kind)
+		rules := obj.Attribute("rules")
+		require.NotNil(t, rules)
+		assert.True(t, rules.Optional)
+		assert.Equal(t, TypeList, rules.Type.Kind)
+		assert.Equal(t, TypeNumber, rules.Type.Element.Attribute("port").Type.Kind)
+	})
+
+	t.Run("canonical string form", func(t *testing.T) {
+		spec, err := ParseTypeString("map(object({enabled=bool, tags=optional(list(string))}))")
+		require.NoError(t, err)
+		assert.Equal(t, "map(object({enabled = bool, tags = optional(list(string))}))", spec.String())
+
+		tuple, err := ParseTypeString("tuple([string,number])")
+		require.NoError(t, err)
+		assert.Equal(t, "tuple([string, number])", tuple.String())
+	})
+
+	t.Run("invalid type specifications", func(t *testing.T) {
+		invalid := []string{
+			"list",
+			"strings",
+			"list(string, number)",
+			"optional(string)",
+			"object({ name = optional(string, 1, 2) })",
+			"tuple(string)",
+			"foo(string)",
+			`"string"`,
+		}
+		for _, src := range invalid {
+			_, err := ParseTypeString(src)
+			assert.Error(t, err, "expected %q to be rejected", src)
+		}
+	})
+}
+
+func TestParseFiles_TypeSpec(t *testing.T) {
+	t.Run("variable carries decoded type", func(t *testing.T) {
+		parser := NewParser()
+		tfContent := `
+variable "services" {
+  type = list(object({
+    name = string
+    port = optional(number, 80)
+  }))
+}
+
+variable "untyped" {
+  default = "value"
+}
+`
+		files := map[string]io.Reader{
+			"variables.tf": strings.NewReader(tfContent),
+		}
+
+		result, err := parser.ParseFiles(files)
+		require.NoError(t, err)
+		require.Len(t, result.Variables, 2)
+		assert.Empty(t, result.Diagnostics)
+
+		var services, untyped Variable
+		for _, v := range result.Variables {
+			switch v.Name {
+			case "services":
+				services = v
+			case "untyped":
+				untyped = v
+			}
+		}
+
+		require.NotNil(t, services.TypeSpec)
+		assert.Equal(t, TypeList, services.TypeSpec.Kind)
+		assert.Equal(t, TypeObject, services.TypeSpec.Element.Kind)
+		assert.True(t, services.TypeSpec.Element.Attribute("port").Optional)
+
+		// The raw type string is still available alongside the decoded form
+		assert.Contains(t, services.Type, "optional(number, 80)")
+
+		assert.Nil(t, untyped.TypeSpec)
+	})
+
+	t.Run("invalid type is reported", func(t *testing.T) {
+		parser := NewParser()
+		tfContent := `
+variable "broken" {
+  type = lists(string)
+}
+`
+		files := map[string]io.Reader{
+			"variables.tf": strings.NewReader(tfContent),
+		}
+
+		result, err := parser.ParseFiles(files)
+		require.Error(t, err)
+		require.Len(t, result.Variables, 1)
+		assert.Nil(t, result.Variables[0].TypeSpec)
+		require.Len(t, result.Diagnostics, 1)
+		assert.Equal(t, "Invalid type specification", result.Diagnostics[0].Summary)
+	})
+}
@@ -0,0 +1,195 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// ConvertCtyValue converts a cty.Value into plain Go values that marshal to
+// the same JSON literal the value was written as: nil, bool, string,
+// json.Number, []interface{} and map[string]interface{}
+func ConvertCtyValue(val cty.Value) (interface{}, error) {
+	if val.ContainsMarked() {
+		return nil, fmt.Errorf("value contains marked (e.g. sensitive) data")
+	}
+	if !val.IsWhollyKnown() {
+		return nil, fmt.Errorf("value is not known until apply")
+	}
+	return ctyToGo(val)
+}
+
+// ctyToGo recursively converts a known, unmarked cty.Value
+func ctyToGo(val cty.Value) (interface{}, error) {
+	if val.IsNull() {
+		return nil, nil
+	}
+
+	ty := val.Type()
+	switch {
+	case ty == cty.String:
+		return val.AsString(), nil
+
+	case ty == cty.Number:
+		// Keep the exact decimal representation rather than rounding through float64
+		return json.Number(val.AsBigFloat().Text('f', -1)), nil
+
+	case ty == cty.Bool:
+		return val.True(), nil
+
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		items := make([]interface{}, 0, val.LengthInt())
+		for it := val.ElementIterator(); it.Next(); {
+			_, elem := it.Element()
+			item, err := ctyToGo(elem)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+
+	case ty.IsMapType() || ty.IsObjectType():
+		obj := make(map[string]interface{}, val.LengthInt())
+		for it := val.ElementIterator(); it.Next(); {
+			key, elem := it.Element()
+			item, err := ctyToGo(elem)
+			if err != nil {
+				return nil, err
+			}
+			obj[key.AsString()] = item
+		}
+		return obj, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported value type %s", ty.FriendlyName())
+	}
+}
+
+// ConformValue converts val to the type constraint described by spec and fills
+// in optional attribute defaults, the same way Terraform prepares a variable value
+func ConformValue(val cty.Value, spec *TypeSpec) (cty.Value, error) {
+	converted, err := convert.Convert(val, spec.CtyType())
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return applyOptionalDefaults(converted, spec)
+}
+
+// applyOptionalDefaults replaces null optional() attributes that declare a
+// default with that default, recursing through nested collections and objects
+func applyOptionalDefaults(val cty.Value, spec *TypeSpec) (cty.Value, error) {
+	if val.IsNull() || !val.IsKnown() {
+		return val, nil
+	}
+
+	ty := val.Type()
+	switch spec.Kind {
+	case TypeObject:
+		if !ty.IsObjectType() {
+			return val, nil
+		}
+		attrs := make(map[string]cty.Value, len(ty.AttributeTypes()))
+		for name := range ty.AttributeTypes() {
+			attrs[name] = val.GetAttr(name)
+		}
+		for _, attr := range spec.Attributes {
+			attrVal, exists := attrs[attr.Name]
+			if !exists {
+				continue
+			}
+			if attrVal.IsNull() && attr.Default != nil {
+				def, err := ConvertGoValue(attr.Default)
+				if err != nil {
+					return cty.NilVal, err
+				}
+				if def, err = convert.Convert(def, attr.Type.CtyType()); err != nil {
+					return cty.NilVal, err
+				}
+				attrVal = def
+			}
+			conformed, err := applyOptionalDefaults(attrVal, attr.Type)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			attrs[attr.Name] = conformed
+		}
+		return cty.ObjectVal(attrs), nil
+
+	case TypeList, TypeSet, TypeMap:
+		if val.LengthInt() == 0 {
+			return val, nil
+		}
+		keys := make([]cty.Value, 0, val.LengthInt())
+		elems := make([]cty.Value, 0, val.LengthInt())
+		for it := val.ElementIterator(); it.Next(); {
+			key, elem := it.Element()
+			conformed, err := applyOptionalDefaults(elem, spec.Element)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			keys = append(keys, key)
+			elems = append(elems, conformed)
+		}
+		if !sameType(elems) {
+			return val, nil
+		}
+		switch spec.Kind {
+		case TypeMap:
+			m := make(map[string]cty.Value, len(keys))
+			for i, key := range keys {
+				m[key.AsString()] = elems[i]
+			}
+			return cty.MapVal(m), nil
+		case TypeSet:
+			return cty.SetVal(elems), nil
+		default:
+			return cty.ListVal(elems), nil
+		}
+
+	case TypeTuple:
+		if !ty.IsTupleType() || len(ty.TupleElementTypes()) != len(spec.Elements) {
+			return val, nil
+		}
+		elems := make([]cty.Value, 0, len(spec.Elements))
+		for i, elemSpec := range spec.Elements {
+			conformed, err := applyOptionalDefaults(val.Index(cty.NumberIntVal(int64(i))), elemSpec)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			elems = append(elems, conformed)
+		}
+		return cty.TupleVal(elems), nil
+	}
+
+	return val, nil
+}
+
+// sameType reports whether all values share one type, which cty requires
+// when rebuilding list, set and map values
+func sameType(values []cty.Value) bool {
+	for _, v := range values[1:] {
+		if !v.Type().Equals(values[0].Type()) {
+			return false
+		}
+	}
+	return true
+}
+
+// ConvertGoValue converts plain Go values, such as those produced by
+// ConvertCtyValue or decoded from JSON or YAML, into a cty.Value of the type
+// their JSON encoding implies
+func ConvertGoValue(v interface{}) (cty.Value, error) {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	ty, err := ctyjson.ImpliedType(buf)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return ctyjson.Unmarshal(buf, ty)
+}
@@ -0,0 +1,240 @@
+package parser
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// parseSingleVariable parses a snippet containing one variable block. Any
+// diagnostics are left on the result for the caller to inspect
+func parseSingleVariable(t *testing.T, tfContent string) (Variable, *ParseResult) {
+	t.Helper()
+
+	result, _ := NewParser().ParseFiles(map[string]io.Reader{
+		"variables.tf": strings.NewReader(tfContent),
+	})
+	require.Len(t, result.Variables, 1)
+	return result.Variables[0], result
+}
+
+func TestParseFiles_DefaultValues(t *testing.T) {
+	t.Run("primitive defaults", func(t *testing.T) {
+		v, _ := parseSingleVariable(t, `
+variable "port" {
+  type    = number
+  default = 8080
+}
+`)
+		assert.Equal(t, json.Number("8080"), v.Default)
+
+		v, _ = parseSingleVariable(t, `
+variable "ratio" {
+  type    = number
+  default = 0.25
+}
+`)
+		assert.Equal(t, json.Number("0.25"), v.Default)
+
+		v, _ = parseSingleVariable(t, `
+variable "enabled" {
+  type    = bool
+  default = true
+}
+`)
+		assert.Equal(t, true, v.Default)
+
+		v, _ = parseSingleVariable(t, `
+variable "name" {
+  type    = string
+  default = "web"
+}
+`)
+		assert.Equal(t, "web", v.Default)
+	})
+
+	t.Run("null default", func(t *testing.T) {
+		v, result := parseSingleVariable(t, `
+variable "region" {
+  type    = string
+  default = null
+}
+`)
+		assert.Nil(t, v.Default)
+		assert.False(t, v.Required)
+		assert.Empty(t, result.Diagnostics)
+	})
+
+	t.Run("list and map defaults", func(t *testing.T) {
+		v, _ := parseSingleVariable(t, `
+variable "cidrs" {
+  type    = list(string)
+  default = ["10.0.0.0/8", "172.16.0.0/12"]
+}
+`)
+		assert.Equal(t, []interface{}{"10.0.0.0/8", "172.16.0.0/12"}, v.Default)
+
+		v, _ = parseSingleVariable(t, `
+variable "tags" {
+  type = map(string)
+  default = {
+    Environment = "production"
+  }
+}
+`)
+		assert.Equal(t, map[string]interface{}{"Environment": "production"}, v.Default)
+	})
+
+	t.Run("default converted to declared type", func(t *testing.T) {
+		v, _ := parseSingleVariable(t, `
+variable "settings" {
+  type = map(string)
+  default = {
+    target_value = 70
+    enabled      = true
+  }
+}
+`)
+		assert.Equal(t, map[string]interface{}{"target_value": "70", "enabled": "true"}, v.Default)
+	})
+
+	t.Run("optional attribute defaults applied", func(t *testing.T) {
+		v, _ := parseSingleVariable(t, `
+variable "server" {
+  type = object({
+    name    = string
+    port    = optional(number, 80)
+    tags    = optional(map(string))
+  })
+  default = {
+    name = "web"
+  }
+}
+`)
+		assert.Equal(t, map[string]interface{}{
+			"name": "web",
+			"port": json.Number("80"),
+			"tags": nil,
+		}, v.Default)
+	})
+
+	t.Run("optional defaults applied inside collections", func(t *testing.T) {
+		v, _ := parseSingleVariable(t, `
+variable "rules" {
+  type = list(object({
+    port     = number
+    protocol = optional(string, "tcp")
+  }))
+  default = [
+    { port = 80 },
+    { port = 53, protocol = "udp" },
+  ]
+}
+`)
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"port": json.Number("80"), "protocol": "tcp"},
+			map[string]interface{}{"port": json.Number("53"), "protocol": "udp"},
+		}, v.Default)
+	})
+
+	t.Run("optional attribute default recorded on type", func(t *testing.T) {
+		v, _ := parseSingleVariable(t, `
+variable "server" {
+  type = object({
+    port = optional(number, 80)
+    tags = optional(map(string), {})
+  })
+}
+`)
+		require.NotNil(t, v.TypeSpec)
+		assert.Equal(t, json.Number("80"), v.TypeSpec.Attribute("port").Default)
+		assert.Equal(t, map[string]interface{}{}, v.TypeSpec.Attribute("tags").Default)
+	})
+
+	t.Run("untyped default kept as written", func(t *testing.T) {
+		v, _ := parseSingleVariable(t, `
+variable "anything" {
+  default = { size = 3, names = ["a", "b"] }
+}
+`)
+		assert.Equal(t, map[string]interface{}{
+			"size":  json.Number("3"),
+			"names": []interface{}{"a", "b"},
+		}, v.Default)
+	})
+
+	t.Run("default marshals to the written literal", func(t *testing.T) {
+		v, _ := parseSingleVariable(t, `
+variable "config" {
+  type = object({
+    replicas = number
+    labels   = map(string)
+  })
+  default = {
+    replicas = 3
+    labels   = { app = "web" }
+  }
+}
+`)
+		out, err := json.Marshal(v.Default)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"replicas": 3, "labels": {"app": "web"}}`, string(out))
+	})
+
+	t.Run("non-literal default is reported", func(t *testing.T) {
+		v, result := parseSingleVariable(t, `
+variable "name" {
+  type    = string
+  default = var.other
+}
+`)
+		assert.Nil(t, v.Default)
+		require.Len(t, result.Diagnostics, 1)
+		assert.Equal(t, SeverityError, result.Diagnostics[0].Severity)
+		assert.Equal(t, "Invalid default value for variable", result.Diagnostics[0].Summary)
+	})
+
+	t.Run("incompatible default is reported", func(t *testing.T) {
+		_, result := parseSingleVariable(t, `
+variable "count" {
+  type    = number
+  default = "many"
+}
+`)
+		require.Len(t, result.Diagnostics, 1)
+		assert.Contains(t, result.Diagnostics[0].Detail, "not compatible")
+	})
+}
+
+func TestConvertCtyValue(t *testing.T) {
+	t.Run("nested values", func(t *testing.T) {
+		val := cty.ObjectVal(map[string]cty.Value{
+			"list": cty.ListVal([]cty.Value{cty.StringVal("a")}),
+			"set":  cty.SetVal([]cty.Value{cty.NumberIntVal(1)}),
+			"null": cty.NullVal(cty.String),
+		})
+
+		out, err := ConvertCtyValue(val)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"list": []interface{}{"a"},
+			"set":  []interface{}{json.Number("1")},
+			"null": nil,
+		}, out)
+	})
+
+	t.Run("unknown values are rejected", func(t *testing.T) {
+		_, err := ConvertCtyValue(cty.ListVal([]cty.Value{cty.UnknownVal(cty.String)}))
+		assert.Error(t, err)
+	})
+
+	t.Run("marked values are rejected", func(t *testing.T) {
+		_, err := ConvertCtyValue(cty.StringVal("secret").Mark("sensitive"))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,376 @@
+package scaffold
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/samart/terraform-schema-generator/pkg/converter"
+	"github.com/samart/terraform-schema-generator/pkg/parser"
+)
+
+// Placeholder starts the line comment of every value the scaffold made up
+// rather than took from a default. Placeholders have the right type but
+// may not satisfy the variable's constraints until they are filled in
+const Placeholder = "TODO"
+
+// placeholderMapKey is the key of the single entry written for maps whose
+// values are structured
+const placeholderMapKey = "example"
+
+// Option configures the scaffold
+type Option func(*config)
+
+type config struct {
+	minimal bool
+}
+
+// WithMinimal limits the scaffold to required variables
+func WithMinimal() Option {
+	return func(c *config) {
+		c.minimal = true
+	}
+}
+
+// YAML renders an inputs file for a module with every variable, its
+// description as a comment and its default as the value. Required
+// variables get placeholders of their type, with objects expanded into
+// skeletons of their attributes. The schema supplies enums and bounds so
+// that placeholders satisfy them where it can; it may be nil
+func YAML(result *parser.ParseResult, schema *converter.JSONSchema7, opts ...Option) ([]byte, error) {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	var buf bytes.Buffer
+	for _, variable := range result.Variables {
+		if cfg.minimal && !variable.Required {
+			continue
+		}
+
+		var property *converter.Property
+		if schema != nil {
+			if p, ok := schema.Properties[variable.Name]; ok {
+				property = &p
+			}
+		}
+
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: variable.Name, HeadComment: headComment(variable)}
+		spec := variableTypeSpec(variable)
+		var value *yaml.Node
+		if variable.Required {
+			value = placeholderNode(spec, property)
+			markPlaceholder(key, value, "required "+typeName(spec))
+		} else {
+			var err error
+			if value, err = valueNode(variable.Default, spec); err != nil {
+				return nil, fmt.Errorf("failed to render default of variable %q: %w", variable.Name, err)
+			}
+		}
+
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		if err := encode(&buf, &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{key, value}}); err != nil {
+			return nil, fmt.Errorf("failed to render variable %q: %w", variable.Name, err)
+		}
+	}
+
+	// An empty document decodes as null, which no schema accepts
+	if buf.Len() == 0 {
+		buf.WriteString("{}\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// encode writes a node as YAML with two space indentation
+func encode(buf *bytes.Buffer, node *yaml.Node) error {
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// headComment describes a variable above its key
+func headComment(variable parser.Variable) string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(variable.Description), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, "# "+line)
+		}
+	}
+	if variable.Sensitive {
+		lines = append(lines, "# Sensitive: keep this value out of version control")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// markPlaceholder attaches the placeholder comment to the key of a
+// structured value, or to the value itself when it is a scalar
+func markPlaceholder(key, value *yaml.Node, what string) {
+	comment := fmt.Sprintf("# %s: %s", Placeholder, what)
+	if value.Kind == yaml.ScalarNode || len(value.Content) == 0 {
+		value.LineComment = comment
+	} else {
+		key.LineComment = comment
+	}
+}
+
+// placeholderNode builds a value of the given type for the user to fill in.
+// Objects are expanded into their attributes: required ones get nested
+// placeholders, optional ones their default or a marked placeholder
+func placeholderNode(spec *parser.TypeSpec, property *converter.Property) *yaml.Node {
+	if property != nil && len(property.Enum) > 0 {
+		if node, err := valueNode(property.Enum[0], spec); err == nil {
+			return node
+		}
+	}
+	if spec == nil {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	}
+
+	switch spec.Kind {
+	case parser.TypeString:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "", Style: yaml.DoubleQuotedStyle}
+
+	case parser.TypeNumber:
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: numberPlaceholder(property)}
+
+	case parser.TypeBool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "false"}
+
+	case parser.TypeList, parser.TypeSet:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		if isStructured(spec.Element) || (property != nil && property.MinItems != nil && *property.MinItems > 0) {
+			node.Style = 0
+			node.Content = []*yaml.Node{placeholderNode(spec.Element, itemsProperty(property))}
+		}
+		return node
+
+	case parser.TypeTuple:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		var items []converter.Property
+		if property != nil {
+			items, _ = property.Items.([]converter.Property)
+		}
+		for i, elem := range spec.Elements {
+			var itemProperty *converter.Property
+			if i < len(items) {
+				itemProperty = &items[i]
+			}
+			node.Content = append(node.Content, placeholderNode(elem, itemProperty))
+		}
+		return node
+
+	case parser.TypeMap:
+		node := &yaml.Node{Kind: yaml.MappingNode, Style: yaml.FlowStyle}
+		if isStructured(spec.Element) {
+			var valueProperty *converter.Property
+			if property != nil {
+				valueProperty, _ = property.AdditionalProperties.(*converter.Property)
+			}
+			key := &yaml.Node{Kind: yaml.ScalarNode, Value: placeholderMapKey}
+			value := placeholderNode(spec.Element, valueProperty)
+			markPlaceholder(key, value, "map key")
+			node.Style = 0
+			node.Content = []*yaml.Node{key, value}
+		}
+		return node
+
+	case parser.TypeObject:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for _, attr := range spec.Attributes {
+			var attrProperty *converter.Property
+			if property != nil {
+				if p, ok := property.Properties[attr.Name]; ok {
+					attrProperty = &p
+				}
+			}
+
+			key := &yaml.Node{Kind: yaml.ScalarNode, Value: attr.Name}
+			var value *yaml.Node
+			if attr.Optional && attr.Default != nil {
+				if v, err := valueNode(attr.Default, attr.Type); err == nil {
+					value = v
+				}
+			}
+			if value == nil {
+				value = placeholderNode(attr.Type, attrProperty)
+				what := typeName(attr.Type)
+				if attr.Optional {
+					what = "optional " + what
+				}
+				markPlaceholder(key, value, what)
+			}
+			node.Content = append(node.Content, key, value)
+		}
+		return node
+	}
+
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+}
+
+// numberPlaceholder returns the smallest number the schema allows, or 0
+func numberPlaceholder(property *converter.Property) string {
+	switch {
+	case property == nil:
+		return "0"
+	case property.Minimum != nil:
+		return formatNumber(*property.Minimum)
+	case property.ExclusiveMinimum != nil:
+		return formatNumber(*property.ExclusiveMinimum + 1)
+	case property.Maximum != nil && *property.Maximum < 0:
+		return formatNumber(*property.Maximum)
+	case property.ExclusiveMaximum != nil && *property.ExclusiveMaximum <= 0:
+		return formatNumber(*property.ExclusiveMaximum - 1)
+	}
+	return "0"
+}
+
+// formatNumber formats a schema bound as a YAML number
+func formatNumber(n float64) string {
+	return fmt.Sprintf("%g", n)
+}
+
+// itemsProperty returns the element schema of a list or set property
+func itemsProperty(property *converter.Property) *converter.Property {
+	if property == nil {
+		return nil
+	}
+	items, _ := property.Items.(*converter.Property)
+	return items
+}
+
+// isStructured reports whether values of the type have attributes or
+// elements worth expanding into a skeleton
+func isStructured(spec *parser.TypeSpec) bool {
+	if spec == nil {
+		return false
+	}
+	switch spec.Kind {
+	case parser.TypeObject, parser.TypeTuple:
+		return true
+	case parser.TypeList, parser.TypeSet, parser.TypeMap:
+		return isStructured(spec.Element)
+	}
+	return false
+}
+
+// valueNode builds the YAML for a default value. Object attributes keep
+// their declaration order, map keys are sorted. Null optional attributes
+// are left out, which is how they are unset in inputs, while other nulls
+// are written out, as required attributes must be present
+func valueNode(v interface{}, spec *parser.TypeSpec) (*yaml.Node, error) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		node := &yaml.Node{Kind: yaml.MappingNode, Style: yaml.FlowStyle}
+		for _, name := range orderedKeys(value, spec) {
+			var attrSpec *parser.TypeSpec
+			if spec != nil {
+				if attr := spec.Attribute(name); attr != nil {
+					if value[name] == nil && attr.Optional {
+						continue
+					}
+					attrSpec = attr.Type
+				} else if spec.Kind == parser.TypeMap {
+					attrSpec = spec.Element
+				}
+			}
+			child, err := valueNode(value[name], attrSpec)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, child)
+		}
+		if len(node.Content) > 0 {
+			node.Style = 0
+		}
+		return node, nil
+
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		if len(value) == 0 {
+			node.Style = yaml.FlowStyle
+		}
+		for i, item := range value {
+			var itemSpec *parser.TypeSpec
+			if spec != nil {
+				if spec.Kind == parser.TypeTuple && i < len(spec.Elements) {
+					itemSpec = spec.Elements[i]
+				} else {
+					itemSpec = spec.Element
+				}
+			}
+			child, err := valueNode(item, itemSpec)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		return node, nil
+
+	case json.Number:
+		// Encoded as is, Node.Encode would quote it as a string
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: value.String()}, nil
+	}
+
+	node := &yaml.Node{}
+	if err := node.Encode(v); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// orderedKeys returns the keys of an object value in attribute declaration
+// order, followed by any other keys sorted by name
+func orderedKeys(value map[string]interface{}, spec *parser.TypeSpec) []string {
+	keys := make([]string, 0, len(value))
+	seen := make(map[string]bool, len(value))
+	if spec != nil && spec.Kind == parser.TypeObject {
+		for _, attr := range spec.Attributes {
+			if _, ok := value[attr.Name]; ok {
+				keys = append(keys, attr.Name)
+				seen[attr.Name] = true
+			}
+		}
+	}
+
+	var rest []string
+	for key := range value {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
+
+// variableTypeSpec returns the decoded type constraint of a variable, or
+// nil when it has none or it cannot be decoded
+func variableTypeSpec(variable parser.Variable) *parser.TypeSpec {
+	if variable.TypeSpec != nil || variable.Type == "" {
+		return variable.TypeSpec
+	}
+	spec, _ := parser.ParseTypeString(variable.Type)
+	return spec
+}
+
+// typeName names a type for placeholder comments. Object and tuple types
+// are not spelled out since the placeholder already shows their structure
+func typeName(spec *parser.TypeSpec) string {
+	if spec == nil {
+		return string(parser.TypeAny)
+	}
+	switch spec.Kind {
+	case parser.TypeList, parser.TypeSet, parser.TypeMap:
+		return fmt.Sprintf("%s(%s)", spec.Kind, typeName(spec.Element))
+	}
+	return string(spec.Kind)
+}
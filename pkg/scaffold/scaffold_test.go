@@ -0,0 +1,255 @@
+package scaffold
+
+import (
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/samart/terraform-schema-generator/pkg/converter"
+	"github.com/samart/terraform-schema-generator/pkg/generator"
+	"github.com/samart/terraform-schema-generator/pkg/parser"
+	"github.com/samart/terraform-schema-generator/pkg/validator"
+)
+
+const scaffoldTestConfig = `
+variable "name" {
+  type        = string
+  description = "Name of the service"
+  validation {
+    condition     = length(var.name) >= 3
+    error_message = "At least 3 characters."
+  }
+}
+
+variable "environment" {
+  type = string
+  validation {
+    condition     = contains(["dev", "prod"], var.environment)
+    error_message = "Unknown environment."
+  }
+}
+
+variable "replicas" {
+  type = number
+  validation {
+    condition     = var.replicas >= 1
+    error_message = "At least one replica."
+  }
+}
+
+variable "ports" {
+  type = list(object({
+    number   = number
+    protocol = optional(string, "tcp")
+    cidrs    = optional(list(string))
+  }))
+}
+
+variable "buckets" {
+  type = map(object({
+    versioning = bool
+  }))
+}
+
+variable "pair" {
+  type = tuple([string, number])
+}
+
+variable "password" {
+  type      = string
+  sensitive = true
+}
+
+variable "tags" {
+  type        = map(string)
+  description = "Tags for all resources"
+  default = {
+    team = "core"
+    app  = "web"
+  }
+}
+
+variable "zone" {
+  type    = string
+  default = null
+}
+`
+
+// parseScaffoldTestConfig parses scaffoldTestConfig and converts it
+func parseScaffoldTestConfig(t *testing.T) (*parser.ParseResult, *converter.JSONSchema7) {
+	t.Helper()
+
+	result, err := parser.NewParser().ParseFiles(map[string]io.Reader{
+		"variables.tf": strings.NewReader(scaffoldTestConfig),
+	})
+	require.NoError(t, err)
+	schema, err := converter.NewConverter().ConvertToJSONSchema7(result)
+	require.NoError(t, err)
+	return result, schema
+}
+
+func TestYAML(t *testing.T) {
+	result, schema := parseScaffoldTestConfig(t)
+
+	out, err := YAML(result, schema)
+	require.NoError(t, err)
+	assert.Equal(t, `# Name of the service
+name: "" # TODO: required string
+
+environment: dev # TODO: required string
+
+replicas: 1 # TODO: required number
+
+ports: # TODO: required list(object)
+  - number: 0 # TODO: number
+    protocol: tcp
+    cidrs: [] # TODO: optional list(string)
+
+buckets: # TODO: required map(object)
+  example: # TODO: map key
+    versioning: false # TODO: bool
+
+pair: # TODO: required tuple
+  - ""
+  - 0
+
+# Sensitive: keep this value out of version control
+password: "" # TODO: required string
+
+# Tags for all resources
+tags:
+  app: web
+  team: core
+
+zone: null
+`, string(out))
+}
+
+func TestYAML_NullAttributes(t *testing.T) {
+	result, err := parser.NewParser().ParseFiles(map[string]io.Reader{
+		"variables.tf": strings.NewReader(`
+variable "settings" {
+  type = object({
+    name   = string
+    prefix = optional(string)
+    suffix = optional(string, "-x")
+  })
+  default = { name = null }
+}
+`),
+	})
+	require.NoError(t, err)
+	schema, err := converter.NewConverter().ConvertToJSONSchema7(result)
+	require.NoError(t, err)
+
+	out, err := YAML(result, schema)
+	require.NoError(t, err)
+
+	// The required attribute keeps its null, the unset optional one is
+	// left out
+	assert.Equal(t, "settings:\n  name: null\n  suffix: -x\n", string(out))
+}
+
+func TestYAML_Minimal(t *testing.T) {
+	result, schema := parseScaffoldTestConfig(t)
+
+	out, err := YAML(result, schema, WithMinimal())
+	require.NoError(t, err)
+
+	var values map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(out, &values))
+	assert.Len(t, values, 7)
+	assert.NotContains(t, values, "tags")
+	assert.NotContains(t, values, "zone")
+}
+
+func TestYAML_WithoutSchema(t *testing.T) {
+	result, _ := parseScaffoldTestConfig(t)
+
+	out, err := YAML(result, nil, WithMinimal())
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "environment: \"\" # TODO: required string\n")
+	assert.Contains(t, string(out), "replicas: 0 # TODO: required number\n")
+}
+
+// TestYAML_ValidatesAgainstSchema checks that every scaffold passes schema
+// validation apart from the values marked as placeholders
+func TestYAML_ValidatesAgainstSchema(t *testing.T) {
+	modules := map[string]func(t *testing.T) (*parser.ParseResult, *converter.JSONSchema7){
+		"test config": parseScaffoldTestConfig,
+	}
+	for _, name := range []string{"terraform-aws-dynamodb-table", "terraform-aws-ecs"} {
+		dir := filepath.Join("..", "..", "testdata", name)
+		modules[name] = func(t *testing.T) (*parser.ParseResult, *converter.JSONSchema7) {
+			gen := generator.New().FromDirectory(dir).Parse()
+			result, err := gen.ParseResult()
+			require.NoError(t, err)
+			schema, err := gen.Convert().Schema()
+			require.NoError(t, err)
+			return result, schema
+		}
+	}
+
+	for name, parse := range modules {
+		t.Run(name, func(t *testing.T) {
+			result, schema := parse(t)
+			v, err := validator.NewInputValidator(schema)
+			require.NoError(t, err)
+
+			for _, opts := range [][]Option{nil, {WithMinimal()}} {
+				out, err := YAML(result, schema, opts...)
+				require.NoError(t, err)
+
+				var doc yaml.Node
+				require.NoError(t, yaml.Unmarshal(out, &doc))
+				placeholders := map[string]bool{}
+				collectPlaceholders(&doc, "", placeholders)
+
+				values, err := validator.DecodeInput(out, validator.FormatYAML)
+				require.NoError(t, err)
+				validation, err := v.ValidateValues(values)
+				require.NoError(t, err)
+				for _, inputErr := range validation.Errors {
+					assert.True(t, placeholders[inputErr.Path], "unexpected error outside placeholders: %s", inputErr.Error())
+				}
+			}
+		})
+	}
+}
+
+// collectPlaceholders records the JSON pointers of the values marked as
+// placeholders, and of everything nested in them
+func collectPlaceholders(node *yaml.Node, path string, placeholders map[string]bool) {
+	marked := func(n *yaml.Node) bool {
+		return strings.HasPrefix(n.LineComment, "# "+Placeholder)
+	}
+	if placeholders[path] || marked(node) {
+		placeholders[path] = true
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			collectPlaceholders(child, path, placeholders)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			childPath := path + "/" + strconv.Itoa(i)
+			placeholders[childPath] = placeholders[path]
+			collectPlaceholders(child, childPath, placeholders)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			childPath := path + "/" + key.Value
+			placeholders[childPath] = placeholders[path] || marked(key)
+			collectPlaceholders(node.Content[i+1], childPath, placeholders)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package tfvars
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/samart/terraform-schema-generator/pkg/parser"
+)
+
+// SecretMode controls what happens to the values of sensitive and
+// ephemeral variables
+type SecretMode string
+
+const (
+	// SecretsInclude writes secrets to the variable definitions like any
+	// other value
+	SecretsInclude SecretMode = "include"
+
+	// SecretsOmit leaves secrets out entirely
+	SecretsOmit SecretMode = "omit"
+
+	// SecretsEnv moves secrets to TF_VAR_ environment variable exports
+	SecretsEnv SecretMode = "env"
+)
+
+// ParseSecretMode returns the secret mode with the given name
+func ParseSecretMode(name string) (SecretMode, error) {
+	switch mode := SecretMode(name); mode {
+	case SecretsInclude, SecretsOmit, SecretsEnv:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid secret mode %q: must be %s, %s or %s", name, SecretsInclude, SecretsOmit, SecretsEnv)
+}
+
+// Converter turns input values into Terraform variable definitions for a
+// module, coercing each value to its variable's declared type
+type Converter struct {
+	variables []parser.Variable
+	secrets   SecretMode
+}
+
+// Option configures a Converter
+type Option func(*Converter)
+
+// WithSecrets sets how the values of sensitive and ephemeral variables are
+// handled. The default is SecretsOmit, since variable definitions files are
+// often committed
+func WithSecrets(mode SecretMode) Option {
+	return func(c *Converter) {
+		c.secrets = mode
+	}
+}
+
+// NewConverter creates a converter for the variables of a parsed module
+func NewConverter(result *parser.ParseResult, opts ...Option) *Converter {
+	c := &Converter{
+		variables: result.Variables,
+		secrets:   SecretsOmit,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Value is a variable value converted to the variable's type
+type Value struct {
+	Name  string
+	Value cty.Value
+}
+
+// Result holds converted values in variable declaration order
+type Result struct {
+	// Values are the values to write to the variable definitions file
+	Values []Value
+
+	// Env are the secrets redirected to environment variables
+	Env []Value
+
+	// Omitted lists the secrets that were left out. With SecretsEnv these
+	// are the null ones, since Terraform reads a TF_VAR_ value as the
+	// string "null" rather than null, so they are left unset instead
+	Omitted []string
+}
+
+// Convert coerces input values, such as a decoded inputs.yaml, to the
+// declared types of the module's variables. Keys that do not name a
+// variable are rejected. Variables without a value are left out, so
+// Terraform uses their defaults
+func (c *Converter) Convert(values interface{}) (*Result, error) {
+	inputs, ok := values.(map[string]interface{})
+	if !ok && values != nil {
+		return nil, fmt.Errorf("input must be an object of variable values, got %T", values)
+	}
+
+	declared := make(map[string]bool, len(c.variables))
+	for _, variable := range c.variables {
+		declared[variable.Name] = true
+	}
+	var unknown []string
+	for name := range inputs {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("undeclared variable(s) %s: the module does not define them", strings.Join(unknown, ", "))
+	}
+
+	result := &Result{}
+	for _, variable := range c.variables {
+		raw, exists := inputs[variable.Name]
+		if !exists {
+			continue
+		}
+
+		val, err := convertValue(variable, raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for variable %q: %w", variable.Name, err)
+		}
+
+		value := Value{Name: variable.Name, Value: val}
+		switch {
+		case !variable.Sensitive && !variable.Ephemeral, c.secrets == SecretsInclude:
+			result.Values = append(result.Values, value)
+		case c.secrets == SecretsEnv && !val.IsNull():
+			if !shellName.MatchString(variable.Name) {
+				return nil, fmt.Errorf("cannot export variable %q: TF_VAR_%s is not a valid shell variable name", variable.Name, variable.Name)
+			}
+			result.Env = append(result.Env, value)
+		default:
+			result.Omitted = append(result.Omitted, variable.Name)
+		}
+	}
+	return result, nil
+}
+
+// convertValue converts a plain Go value to the variable's type, filling in
+// optional attribute defaults as Terraform would
+func convertValue(variable parser.Variable, raw interface{}) (cty.Value, error) {
+	spec := variable.TypeSpec
+	if spec == nil && variable.Type != "" {
+		spec, _ = parser.ParseTypeString(variable.Type)
+	}
+
+	if raw == nil {
+		if spec == nil {
+			return cty.NullVal(cty.DynamicPseudoType), nil
+		}
+		return cty.NullVal(spec.CtyType().WithoutOptionalAttributesDeep()), nil
+	}
+
+	val, err := parser.ConvertGoValue(raw)
+	if err != nil || spec == nil {
+		return val, err
+	}
+	return parser.ConformValue(val, spec)
+}
+
+// JSON renders the values as a terraform.tfvars.json document
+func (r *Result) JSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, value := range r.Values {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		name, _ := json.Marshal(value.Name)
+		buf.Write(name)
+		buf.WriteString(":")
+
+		plain, err := parser.ConvertCtyValue(value.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode variable %q: %w", value.Name, err)
+		}
+		data, err := json.Marshal(plain)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode variable %q: %w", value.Name, err)
+		}
+		buf.Write(data)
+	}
+	buf.WriteString("}")
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteString("\n")
+	return out.Bytes(), nil
+}
+
+// HCL renders the values as a native syntax .tfvars file
+func (r *Result) HCL() []byte {
+	file := hclwrite.NewEmptyFile()
+	body := file.Body()
+	for _, value := range r.Values {
+		body.SetAttributeValue(value.Name, value.Value)
+	}
+	return hclwrite.Format(file.Bytes())
+}
+
+// EnvExports renders the redirected secrets as shell export statements of
+// TF_VAR_ environment variables. Strings are exported as is, other values
+// in the HCL syntax Terraform parses them with
+func (r *Result) EnvExports() []byte {
+	var buf bytes.Buffer
+	for _, value := range r.Env {
+		var raw string
+		if value.Value.Type() == cty.String && !value.Value.IsNull() {
+			raw = value.Value.AsString()
+		} else {
+			raw = string(hclwrite.TokensForValue(value.Value).Bytes())
+		}
+		fmt.Fprintf(&buf, "export TF_VAR_%s=%s\n", value.Name, shellQuote(raw))
+	}
+	return buf.Bytes()
+}
+
+// shellName matches the names a POSIX shell can export. Terraform allows
+// hyphens in variable names, which shells do not
+var shellName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// shellQuote quotes s for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,227 @@
+package tfvars
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/samart/terraform-schema-generator/pkg/parser"
+	"github.com/samart/terraform-schema-generator/pkg/validator"
+)
+
+const tfvarsTestConfig = `
+variable "name" {
+  type = string
+}
+
+variable "replicas" {
+  type    = number
+  default = 1
+}
+
+variable "zones" {
+  type    = set(string)
+  default = []
+}
+
+variable "ports" {
+  type = list(object({
+    number   = number
+    protocol = optional(string, "tcp")
+  }))
+  default = []
+}
+
+variable "db_password" {
+  type      = string
+  sensitive = true
+}
+
+variable "session_token" {
+  type      = string
+  ephemeral = true
+  default   = null
+}
+`
+
+const tfvarsTestInput = `
+name: web
+replicas: "3"
+zones: [b, a]
+ports:
+  - number: 443
+db_password: "it's secret"
+session_token: abc
+`
+
+// convertTestInput converts tfvarsTestInput with the given options
+func convertTestInput(t *testing.T, opts ...Option) *Result {
+	t.Helper()
+
+	parsed, err := parser.NewParser().ParseFiles(map[string]io.Reader{
+		"variables.tf": strings.NewReader(tfvarsTestConfig),
+	})
+	require.NoError(t, err)
+
+	values, err := validator.DecodeInput([]byte(tfvarsTestInput), validator.FormatYAML)
+	require.NoError(t, err)
+
+	result, err := NewConverter(parsed, opts...).Convert(values)
+	require.NoError(t, err)
+	return result
+}
+
+func TestConvert(t *testing.T) {
+	t.Run("JSON coerces values to the declared types", func(t *testing.T) {
+		out, err := convertTestInput(t, WithSecrets(SecretsInclude)).JSON()
+		require.NoError(t, err)
+		assert.Equal(t, `{
+  "name": "web",
+  "replicas": 3,
+  "zones": [
+    "a",
+    "b"
+  ],
+  "ports": [
+    {
+      "number": 443,
+      "protocol": "tcp"
+    }
+  ],
+  "db_password": "it's secret",
+  "session_token": "abc"
+}
+`, string(out))
+	})
+
+	t.Run("HCL", func(t *testing.T) {
+		out := convertTestInput(t, WithSecrets(SecretsInclude)).HCL()
+		assert.Equal(t, `name     = "web"
+replicas = 3
+zones    = ["a", "b"]
+ports = [{
+  number   = 443
+  protocol = "tcp"
+}]
+db_password   = "it's secret"
+session_token = "abc"
+`, string(out))
+	})
+
+	t.Run("secrets omitted by default", func(t *testing.T) {
+		result := convertTestInput(t)
+		assert.Equal(t, []string{"db_password", "session_token"}, result.Omitted)
+		assert.Empty(t, result.Env)
+		assert.NotContains(t, string(result.HCL()), "secret")
+	})
+
+	t.Run("secrets redirected to the environment", func(t *testing.T) {
+		result := convertTestInput(t, WithSecrets(SecretsEnv))
+		assert.Len(t, result.Values, 4)
+		assert.Equal(t, "export TF_VAR_db_password='it'\\''s secret'\nexport TF_VAR_session_token='abc'\n", string(result.EnvExports()))
+	})
+}
+
+func TestConvert_Errors(t *testing.T) {
+	parsed, err := parser.NewParser().ParseFiles(map[string]io.Reader{
+		"variables.tf": strings.NewReader(tfvarsTestConfig),
+	})
+	require.NoError(t, err)
+	c := NewConverter(parsed)
+
+	_, err = c.Convert(map[string]interface{}{"name": "web", "nmae": "typo", "extra": 1})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "undeclared variable(s) extra, nmae")
+
+	_, err = c.Convert(map[string]interface{}{"replicas": "many"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid value for variable "replicas"`)
+
+	_, err = c.Convert([]interface{}{"web"})
+	assert.Error(t, err)
+}
+
+func TestConvert_NullAndComplexEnv(t *testing.T) {
+	parsed, err := parser.NewParser().ParseFiles(map[string]io.Reader{
+		"variables.tf": strings.NewReader(`
+variable "tags" {
+  type      = map(string)
+  sensitive = true
+}
+
+variable "anything" {
+  default = null
+}
+`),
+	})
+	require.NoError(t, err)
+
+	result, err := NewConverter(parsed, WithSecrets(SecretsEnv)).Convert(map[string]interface{}{
+		"tags":     map[string]interface{}{"team": "core"},
+		"anything": nil,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "export TF_VAR_tags='{\n  team = \"core\"\n}'\n", string(result.EnvExports()))
+	assert.Empty(t, result.Omitted)
+	out, err := result.JSON()
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"anything\": null\n}\n", string(out))
+}
+
+func TestConvert_NullSecretsAreNotExported(t *testing.T) {
+	parsed, err := parser.NewParser().ParseFiles(map[string]io.Reader{
+		"variables.tf": strings.NewReader(`
+variable "api_key" {
+  type      = string
+  sensitive = true
+  default   = "from-default"
+}
+`),
+	})
+	require.NoError(t, err)
+
+	result, err := NewConverter(parsed, WithSecrets(SecretsEnv)).Convert(map[string]interface{}{
+		"api_key": nil,
+	})
+	require.NoError(t, err)
+
+	// TF_VAR_api_key=null would set the string "null"
+	assert.Empty(t, result.EnvExports())
+	assert.Empty(t, result.Values)
+	assert.Equal(t, []string{"api_key"}, result.Omitted)
+}
+
+func TestConvert_SecretsWithoutShellName(t *testing.T) {
+	parsed, err := parser.NewParser().ParseFiles(map[string]io.Reader{
+		"variables.tf": strings.NewReader(`
+variable "db-password" {
+  type      = string
+  sensitive = true
+}
+`),
+	})
+	require.NoError(t, err)
+	inputs := map[string]interface{}{"db-password": "hunter2"}
+
+	_, err = NewConverter(parsed, WithSecrets(SecretsEnv)).Convert(inputs)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `cannot export variable "db-password": TF_VAR_db-password is not a valid shell variable name`)
+
+	// Only exporting is a problem
+	result, err := NewConverter(parsed, WithSecrets(SecretsInclude)).Convert(inputs)
+	require.NoError(t, err)
+	assert.Len(t, result.Values, 1)
+}
+
+func TestParseSecretMode(t *testing.T) {
+	mode, err := ParseSecretMode("env")
+	require.NoError(t, err)
+	assert.Equal(t, SecretsEnv, mode)
+
+	_, err = ParseSecretMode("vault")
+	assert.Error(t, err)
+}
@@ -0,0 +1,269 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+
+	"github.com/samart/terraform-schema-generator/pkg/parser"
+)
+
+// ConditionEvaluator validates input values by evaluating the validation
+// conditions of a module's variables with Terraform's function library, so
+// that it reports the same error messages Terraform would at plan time
+type ConditionEvaluator struct {
+	variables   []parser.Variable
+	diagnostics parser.Diagnostics
+}
+
+// NewConditionEvaluator creates a condition evaluator for the variables of
+// a parsed module
+func NewConditionEvaluator(result *parser.ParseResult) *ConditionEvaluator {
+	return &ConditionEvaluator{variables: result.Variables}
+}
+
+// Diagnostics returns the warnings reported by the last validation, such as
+// conditions that refer to values other than input variables and so could
+// not be evaluated
+func (e *ConditionEvaluator) Diagnostics() parser.Diagnostics {
+	return e.diagnostics
+}
+
+// ValidateFile validates an input file, detecting its format from the
+// file extension
+func (e *ConditionEvaluator) ValidateFile(path string) (*InputResult, error) {
+	values, err := DecodeInputFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return e.ValidateValues(values)
+}
+
+// Validate validates an input document in the given format
+func (e *ConditionEvaluator) Validate(data []byte, format InputFormat) (*InputResult, error) {
+	values, err := DecodeInput(data, format)
+	if err != nil {
+		return nil, err
+	}
+	return e.ValidateValues(values)
+}
+
+// ValidateValues prepares each variable's value as Terraform does, converting
+// it to the declared type and applying defaults, then evaluates every
+// validation condition. Failed conditions are reported with the keyword
+// "condition" and the rule's error_message
+func (e *ConditionEvaluator) ValidateValues(values interface{}) (*InputResult, error) {
+	inputs, ok := values.(map[string]interface{})
+	if !ok && values != nil {
+		return nil, fmt.Errorf("input must be an object of variable values, got %T", values)
+	}
+
+	e.diagnostics = nil
+	result := &InputResult{Valid: true, Errors: []InputError{}}
+
+	// Values that cannot be prepared stay unknown, so conditions referring
+	// to them from other variables are skipped rather than misreported
+	prepared := make(map[string]cty.Value, len(e.variables))
+	for _, variable := range e.variables {
+		val, inputErr := prepareValue(variable, inputs)
+		if inputErr != nil {
+			result.Errors = append(result.Errors, *inputErr)
+			val = cty.DynamicVal
+		}
+		prepared[variable.Name] = val
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{"var": cty.ObjectVal(prepared)},
+		Functions: terraformFunctions(),
+	}
+
+	for _, variable := range e.variables {
+		if !prepared[variable.Name].IsWhollyKnown() {
+			continue
+		}
+		for _, rule := range variable.Validations {
+			holds, problem := e.checkCondition(rule, ctx)
+			if holds {
+				continue
+			}
+			result.Errors = append(result.Errors, InputError{
+				Path:         "/" + escapePointer(variable.Name),
+				Keyword:      "condition",
+				Message:      problem,
+				ErrorMessage: rule.ErrorMessage,
+			})
+		}
+	}
+
+	result.Valid = len(result.Errors) == 0
+	return result, nil
+}
+
+// prepareValue returns the value Terraform would assign to a variable for
+// the given inputs, or the error Terraform would raise instead
+func prepareValue(variable parser.Variable, inputs map[string]interface{}) (cty.Value, *InputError) {
+	path := "/" + escapePointer(variable.Name)
+	spec := variable.TypeSpec
+	if spec == nil && variable.Type != "" {
+		spec, _ = parser.ParseTypeString(variable.Type)
+	}
+	if spec == nil {
+		spec = &parser.TypeSpec{Kind: parser.TypeAny}
+	}
+
+	raw, exists := inputs[variable.Name]
+
+	// Non-nullable variables use their default when given null
+	if exists && raw == nil && !variable.Nullable && !variable.Required {
+		exists = false
+	}
+	if !exists {
+		if variable.Required {
+			return cty.NilVal, &InputError{
+				Path:    path,
+				Keyword: "required",
+				Message: fmt.Sprintf("no value for required variable %q", variable.Name),
+			}
+		}
+		raw = variable.Default
+	}
+
+	if raw == nil {
+		if !variable.Nullable {
+			return cty.NilVal, &InputError{
+				Path:    path,
+				Keyword: "type",
+				Message: fmt.Sprintf("variable %q must not be null", variable.Name),
+			}
+		}
+		return cty.NullVal(spec.CtyType().WithoutOptionalAttributesDeep()), nil
+	}
+
+	val, err := parser.ConvertGoValue(raw)
+	if err == nil {
+		val, err = parser.ConformValue(val, spec)
+	}
+	if err != nil {
+		return cty.NilVal, &InputError{
+			Path:    path,
+			Keyword: "type",
+			Message: fmt.Sprintf("invalid value for variable %q: %s", variable.Name, err),
+		}
+	}
+	return val, nil
+}
+
+// checkCondition evaluates a validation condition and describes why it
+// failed. Conditions that cannot be evaluated here, because they call
+// functions or refer to values other than input variables, are reported as
+// warnings and treated as satisfied, as are conditions whose result is
+// unknown. Errors raised while evaluating anything else are caused by the
+// value, which Terraform rejects, so they fail the condition
+func (e *ConditionEvaluator) checkCondition(rule parser.Validation, ctx *hcl.EvalContext) (bool, string) {
+	expr, diags := hclsyntax.ParseExpression([]byte(rule.Condition), rule.Range.Filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		e.warn("Validation condition not evaluated", fmt.Sprintf("The condition %q could not be parsed: %s.", rule.Condition, diags.Error()), rule.Range)
+		return true, ""
+	}
+	if reason := unsupportedCondition(expr, ctx); reason != "" {
+		e.warn("Validation condition not evaluated", fmt.Sprintf("The condition %q could not be evaluated: %s.", rule.Condition, reason), rule.Range)
+		return true, ""
+	}
+
+	val, diags := expr.Value(ctx)
+	if diags.HasErrors() {
+		return false, fmt.Sprintf("validation condition failed to evaluate: %s", diagnosticsDetail(diags))
+	}
+	if !val.IsKnown() {
+		return true, ""
+	}
+
+	val, err := convert.Convert(val, cty.Bool)
+	if err != nil || val.IsNull() {
+		e.warn("Invalid validation condition result", fmt.Sprintf("The condition %q must return true or false.", rule.Condition), rule.Range)
+		return true, ""
+	}
+	if val.False() {
+		return false, fmt.Sprintf("validation condition is false: %s", rule.Condition)
+	}
+	return true, ""
+}
+
+// unsupportedCondition describes what keeps a condition from being evaluated
+// with ctx, such as a reference to a local value or an unknown function, or
+// returns an empty string when nothing does
+func unsupportedCondition(expr hclsyntax.Expression, ctx *hcl.EvalContext) string {
+	variables := ctx.Variables["var"]
+	for _, traversal := range expr.Variables() {
+		if traversal.RootName() != "var" {
+			return fmt.Sprintf("%s is not an input variable", traversalString(traversal))
+		}
+		if len(traversal) < 2 {
+			continue
+		}
+		if attr, ok := traversal[1].(hcl.TraverseAttr); ok && !variables.Type().HasAttribute(attr.Name) {
+			return fmt.Sprintf("var.%s is not declared", attr.Name)
+		}
+	}
+
+	var unknown string
+	hclsyntax.VisitAll(expr, func(node hclsyntax.Node) hcl.Diagnostics {
+		if call, ok := node.(*hclsyntax.FunctionCallExpr); ok && unknown == "" {
+			if _, exists := ctx.Functions[call.Name]; !exists {
+				unknown = call.Name
+			}
+		}
+		return nil
+	})
+	if unknown != "" {
+		return fmt.Sprintf("the function %s is not available outside Terraform", unknown)
+	}
+	return ""
+}
+
+// traversalString formats a reference such as local.max_replicas
+func traversalString(traversal hcl.Traversal) string {
+	name := traversal.RootName()
+	for _, step := range traversal[1:] {
+		attr, ok := step.(hcl.TraverseAttr)
+		if !ok {
+			break
+		}
+		name += "." + attr.Name
+	}
+	return name
+}
+
+// diagnosticsDetail joins the details of error diagnostics, falling back to
+// their summaries
+func diagnosticsDetail(diags hcl.Diagnostics) string {
+	var details []string
+	for _, diag := range diags.Errs() {
+		var hclDiag *hcl.Diagnostic
+		if errors.As(diag, &hclDiag) && hclDiag.Detail != "" {
+			details = append(details, strings.TrimSuffix(hclDiag.Detail, "."))
+		} else {
+			details = append(details, diag.Error())
+		}
+	}
+	return strings.Join(details, "; ")
+}
+
+// warn records a warning about the validation rule at rng
+func (e *ConditionEvaluator) warn(summary, detail string, rng parser.Range) {
+	diag := parser.Diagnostic{
+		Severity: parser.SeverityWarning,
+		Summary:  summary,
+		Detail:   detail,
+	}
+	if rng.Filename != "" {
+		diag.Subject = &rng
+	}
+	e.diagnostics = append(e.diagnostics, diag)
+}
@@ -0,0 +1,223 @@
+package validator
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/samart/terraform-schema-generator/pkg/parser"
+)
+
+const conditionTestConfig = `
+variable "name" {
+  type = string
+  validation {
+    condition     = can(regex("^[a-z-]+$", var.name))
+    error_message = "Use lowercase letters and dashes."
+  }
+  validation {
+    condition     = length(var.name) <= 12
+    error_message = "At most 12 characters."
+  }
+}
+
+variable "cidr" {
+  type    = string
+  default = "10.0.0.0/16"
+  validation {
+    condition     = can(cidrhost(var.cidr, 0))
+    error_message = "Must be a valid CIDR block."
+  }
+}
+
+variable "ports" {
+  type = list(object({
+    number   = number
+    protocol = optional(string, "tcp")
+  }))
+  default = []
+  validation {
+    condition     = alltrue([for p in var.ports : contains(["tcp", "udp"], p.protocol)])
+    error_message = "Protocol must be tcp or udp."
+  }
+}
+
+variable "replicas" {
+  type     = number
+  default  = 1
+  nullable = false
+  validation {
+    condition     = var.replicas >= 1 && var.replicas <= local.max_replicas
+    error_message = "Too many replicas."
+  }
+}
+`
+
+// newTestConditionEvaluator builds a condition evaluator for conditionTestConfig
+func newTestConditionEvaluator(t *testing.T) *ConditionEvaluator {
+	t.Helper()
+
+	result, err := parser.NewParser().ParseFiles(map[string]io.Reader{
+		"variables.tf": strings.NewReader(conditionTestConfig),
+	})
+	require.NoError(t, err)
+	return NewConditionEvaluator(result)
+}
+
+func TestConditionEvaluator(t *testing.T) {
+	e := newTestConditionEvaluator(t)
+
+	t.Run("valid inputs with defaults applied", func(t *testing.T) {
+		result, err := e.Validate([]byte("name: web\nports:\n  - number: 443\n"), FormatYAML)
+		require.NoError(t, err)
+		assert.True(t, result.Valid)
+		assert.Empty(t, result.Errors)
+	})
+
+	t.Run("failed conditions report their error messages", func(t *testing.T) {
+		result, err := e.Validate([]byte(`{"name": "Web_Server_Production", "cidr": "10.0.0.0/33"}`), FormatJSON)
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+
+		messages := make([]string, len(result.Errors))
+		for i, inputErr := range result.Errors {
+			assert.Equal(t, "condition", inputErr.Keyword)
+			messages[i] = inputErr.Path + ": " + inputErr.ErrorMessage
+		}
+		assert.Equal(t, []string{
+			"/name: Use lowercase letters and dashes.",
+			"/name: At most 12 characters.",
+			"/cidr: Must be a valid CIDR block.",
+		}, messages)
+	})
+
+	t.Run("optional attribute defaults are visible to conditions", func(t *testing.T) {
+		result, err := e.Validate([]byte("name = \"web\"\nports = [{ number = 53, protocol = \"icmp\" }, { number = 80 }]\n"), FormatTFVars)
+		require.NoError(t, err)
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, "/ports", result.Errors[0].Path)
+		assert.Equal(t, "Protocol must be tcp or udp.", result.Errors[0].ErrorMessage)
+	})
+
+	t.Run("missing and mistyped values", func(t *testing.T) {
+		result, err := e.ValidateValues(map[string]interface{}{
+			"ports": "none",
+		})
+		require.NoError(t, err)
+		require.Len(t, result.Errors, 2)
+		assert.Equal(t, "/name", result.Errors[0].Path)
+		assert.Equal(t, "required", result.Errors[0].Keyword)
+		assert.Equal(t, "/ports", result.Errors[1].Path)
+		assert.Equal(t, "type", result.Errors[1].Keyword)
+	})
+
+	t.Run("null uses the default of non-nullable variables", func(t *testing.T) {
+		result, err := e.ValidateValues(map[string]interface{}{
+			"name":     "web",
+			"replicas": nil,
+		})
+		require.NoError(t, err)
+		assert.True(t, result.Valid)
+	})
+
+	t.Run("conditions referring to other values are skipped with a warning", func(t *testing.T) {
+		_, err := e.ValidateValues(map[string]interface{}{"name": "web"})
+		require.NoError(t, err)
+
+		diags := e.Diagnostics()
+		require.Len(t, diags, 1)
+		assert.Equal(t, parser.SeverityWarning, diags[0].Severity)
+		assert.Contains(t, diags[0].Detail, "local.max_replicas")
+		require.NotNil(t, diags[0].Subject)
+		assert.Equal(t, "variables.tf", diags[0].Subject.Filename)
+	})
+
+	t.Run("non-object input", func(t *testing.T) {
+		_, err := e.ValidateValues([]interface{}{"web"})
+		assert.Error(t, err)
+	})
+}
+
+func TestTerraformFunctions(t *testing.T) {
+	e := NewConditionEvaluator(&parser.ParseResult{
+		Variables: []parser.Variable{{Name: "x", Type: "string", Required: true, Nullable: true}},
+	})
+
+	conditions := []string{
+		`length("héllo") == 5`,
+		`startswith(var.x, "ab") && endswith(var.x, "yz") && strcontains(var.x, "mn")`,
+		`replace(var.x, "/[a-m]+/", "") == "nopqrstuvwxyz"`,
+		`cidrhost("10.1.0.0/16", 5) == "10.1.0.5"`,
+		`cidrsubnet("10.1.0.0/16", 8, 2) == "10.1.2.0/24"`,
+		`cidrnetmask("10.1.0.0/16") == "255.255.0.0"`,
+		`!can(cidrhost("not-a-cidr", 1))`,
+		`anytrue([false, true]) && !alltrue([true, false])`,
+		`sum([1, 2, 3]) == 6 && one([]) == null`,
+		`try(tonumber("x"), 7) == 7`,
+		`base64decode(base64encode(var.x)) == var.x`,
+	}
+	for _, condition := range conditions {
+		t.Run(condition, func(t *testing.T) {
+			e.variables[0].Validations = []parser.Validation{{Condition: condition, ErrorMessage: "failed"}}
+			result, err := e.ValidateValues(map[string]interface{}{"x": "abcdefghijklmnopqrstuvwxyz"})
+			require.NoError(t, err)
+			assert.Empty(t, e.Diagnostics())
+			assert.True(t, result.Valid, "condition should hold")
+		})
+	}
+}
+
+func TestConditionEvaluator_EvaluationErrors(t *testing.T) {
+	e := NewConditionEvaluator(&parser.ParseResult{
+		Variables: []parser.Variable{
+			{Name: "x", Type: "string", Required: true, Nullable: true},
+			{Name: "zones", Type: "list(string)", Default: []interface{}{"a"}, Nullable: true},
+		},
+	})
+
+	t.Run("errors caused by the value fail the condition", func(t *testing.T) {
+		conditions := map[string]string{
+			`regex("^[a-z]+$", var.x) == var.x`: "pattern did not match",
+			`tonumber(var.x) > 0`:               `cannot convert "ABC" to number`,
+			`var.zones[3] != ""`:                "does not identify an element",
+		}
+		for condition, detail := range conditions {
+			t.Run(condition, func(t *testing.T) {
+				e.variables[0].Validations = []parser.Validation{{Condition: condition, ErrorMessage: "Invalid x."}}
+				result, err := e.ValidateValues(map[string]interface{}{"x": "ABC"})
+				require.NoError(t, err)
+				assert.Empty(t, e.Diagnostics())
+
+				require.Len(t, result.Errors, 1)
+				assert.Equal(t, "/x", result.Errors[0].Path)
+				assert.Equal(t, "condition", result.Errors[0].Keyword)
+				assert.Equal(t, "Invalid x.", result.Errors[0].ErrorMessage)
+				assert.Contains(t, result.Errors[0].Message, detail)
+			})
+		}
+	})
+
+	t.Run("unknown functions and references are skipped with a warning", func(t *testing.T) {
+		conditions := map[string]string{
+			`templatefile("x.tpl", {}) != ""`: "the function templatefile is not available",
+			`var.region != ""`:                "var.region is not declared",
+			`length(var.x) < each.value`:      "each.value is not an input variable",
+		}
+		for condition, detail := range conditions {
+			t.Run(condition, func(t *testing.T) {
+				e.variables[0].Validations = []parser.Validation{{Condition: condition, ErrorMessage: "Invalid x."}}
+				result, err := e.ValidateValues(map[string]interface{}{"x": "ABC"})
+				require.NoError(t, err)
+				assert.True(t, result.Valid)
+
+				diags := e.Diagnostics()
+				require.Len(t, diags, 1)
+				assert.Equal(t, parser.SeverityWarning, diags[0].Severity)
+				assert.Contains(t, diags[0].Detail, detail)
+			})
+		}
+	})
+}
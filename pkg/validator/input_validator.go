@@ -0,0 +1,379 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+
+	"github.com/samart/terraform-schema-generator/pkg/converter"
+	"github.com/samart/terraform-schema-generator/pkg/parser"
+)
+
+// InputFormat identifies the syntax of an input document
+type InputFormat string
+
+const (
+	// FormatYAML is a YAML document
+	FormatYAML InputFormat = "yaml"
+
+	// FormatJSON is a JSON document, including .tfvars.json files
+	FormatJSON InputFormat = "json"
+
+	// FormatTFVars is an HCL variable definitions (.tfvars) file
+	FormatTFVars InputFormat = "tfvars"
+)
+
+// FormatForFilename returns the input format matching a file extension
+func FormatForFilename(filename string) (InputFormat, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	case ".json":
+		return FormatJSON, nil
+	case ".tfvars":
+		return FormatTFVars, nil
+	}
+	return "", fmt.Errorf("unsupported input file %s: expected .yaml, .yml, .json or .tfvars", filename)
+}
+
+// SchemaSource produces the JSON of a schema, as generator.Generator does
+type SchemaSource interface {
+	JSON() ([]byte, error)
+}
+
+// InputValidator validates input documents, such as the values for a
+// module's variables, against a generated schema
+type InputValidator struct {
+	schema     *gojsonschema.Schema
+	properties map[string]converter.Property
+}
+
+// NewInputValidator creates an input validator for a converted schema
+func NewInputValidator(schema *converter.JSONSchema7) (*InputValidator, error) {
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize schema: %w", err)
+	}
+	return NewInputValidatorFromJSON(schemaJSON)
+}
+
+// NewInputValidatorFromSource creates an input validator for the schema a
+// source such as a generator.Generator produces
+func NewInputValidatorFromSource(source SchemaSource) (*InputValidator, error) {
+	schemaJSON, err := source.JSON()
+	if err != nil {
+		return nil, err
+	}
+	return NewInputValidatorFromJSON(schemaJSON)
+}
+
+// NewInputValidatorFromJSON creates an input validator for a schema given as
+// JSON
+func NewInputValidatorFromJSON(schemaJSON []byte) (*InputValidator, error) {
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(schemaJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema: %w", err)
+	}
+
+	// The variable properties are only needed for the Terraform error
+	// messages, so schemas of another shape can still be used
+	var document converter.JSONSchema7
+	_ = json.Unmarshal(schemaJSON, &document)
+
+	return &InputValidator{
+		schema:     schema,
+		properties: document.Properties,
+	}, nil
+}
+
+// InputResult is the outcome of validating an input document
+type InputResult struct {
+	Valid  bool         `json:"valid"`
+	Errors []InputError `json:"errors"`
+}
+
+// InputError describes a value of an input document that does not satisfy
+// the schema
+type InputError struct {
+	// Path is the JSON pointer of the offending value, "" for the document
+	Path string `json:"path"`
+
+	// Keyword is the JSON Schema keyword that failed, such as "required"
+	Keyword string `json:"keyword"`
+
+	// Message describes the failure
+	Message string `json:"message"`
+
+	// ErrorMessage is the error_message of the Terraform validation rule
+	// the failing keyword was derived from, if any
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+// Error formats the error with its location
+func (e InputError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "/"
+	}
+	if e.ErrorMessage != "" {
+		return fmt.Sprintf("%s: %s (%s)", path, e.ErrorMessage, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", path, e.Message)
+}
+
+// ValidateFile validates an input file, detecting its format from the
+// file extension
+func (v *InputValidator) ValidateFile(path string) (*InputResult, error) {
+	values, err := DecodeInputFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return v.ValidateValues(values)
+}
+
+// Validate validates an input document in the given format
+func (v *InputValidator) Validate(data []byte, format InputFormat) (*InputResult, error) {
+	return v.validate("input."+string(format), data, format)
+}
+
+// ValidateValues validates input values that are already decoded, such as
+// the result of unmarshaling a JSON document
+func (v *InputValidator) ValidateValues(values interface{}) (*InputResult, error) {
+	result, err := v.schema.Validate(gojsonschema.NewGoLoader(values))
+	if err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	inputResult := &InputResult{
+		Valid:  result.Valid(),
+		Errors: []InputError{},
+	}
+	for _, resultErr := range result.Errors() {
+		// allOf failures are also reported for the failing subschema
+		if resultErr.Type() == "number_all_of" {
+			continue
+		}
+
+		inputErr := InputError{
+			Path:    jsonPointer(resultErr.Context()),
+			Keyword: schemaKeyword(resultErr.Type()),
+			Message: resultErr.Description(),
+		}
+		inputErr.ErrorMessage = v.terraformErrorMessage(inputErr.Path, inputErr.Keyword, resultErr.Details())
+		inputResult.Errors = append(inputResult.Errors, inputErr)
+	}
+
+	// Properties are validated in map order, so sort for stable output
+	sort.SliceStable(inputResult.Errors, func(i, j int) bool {
+		return inputResult.Errors[i].Path < inputResult.Errors[j].Path
+	})
+	return inputResult, nil
+}
+
+// validate decodes a document and validates its values
+func (v *InputValidator) validate(filename string, data []byte, format InputFormat) (*InputResult, error) {
+	values, err := decodeInput(filename, data, format)
+	if err != nil {
+		return nil, err
+	}
+	return v.ValidateValues(values)
+}
+
+// terraformErrorMessage returns the error_message of the validation rule
+// that produced keyword at the schema location of the value at path: the
+// variable's own schema, one of its allOf entries, or the schema of its
+// elements. Patterns tell apart the allOf entries by the pattern that failed
+func (v *InputValidator) terraformErrorMessage(path, keyword string, details gojsonschema.ErrorDetails) string {
+	segments := strings.Split(path, "/")
+	if len(segments) < 2 || len(segments) > 3 {
+		return ""
+	}
+	property, ok := v.properties[unescapePointer(segments[1])]
+	if !ok {
+		return ""
+	}
+
+	var locations []string
+	if len(segments) == 2 {
+		locations = append(locations, keyword)
+		for i := range property.AllOf {
+			locations = append(locations, fmt.Sprintf("allOf/%d/%s", i, keyword))
+		}
+	} else {
+		locations = append(locations, "items/"+keyword, "additionalProperties/"+keyword)
+	}
+
+	for _, rule := range property.Validations {
+		for _, ruleKeyword := range rule.Keywords {
+			if !slices.Contains(locations, ruleKeyword) {
+				continue
+			}
+			if keyword == "pattern" && fmt.Sprint(details["pattern"]) != patternAt(property, ruleKeyword) {
+				continue
+			}
+			return rule.ErrorMessage
+		}
+	}
+	return ""
+}
+
+// patternAt returns the pattern at a keyword location of a property, which
+// is either pattern or allOf/N/pattern
+func patternAt(property converter.Property, location string) string {
+	var index int
+	if _, err := fmt.Sscanf(location, "allOf/%d/pattern", &index); err == nil && index < len(property.AllOf) {
+		return property.AllOf[index].Pattern
+	}
+	return property.Pattern
+}
+
+// DecodeInput decodes an input document in the given format into plain Go
+// values
+func DecodeInput(data []byte, format InputFormat) (interface{}, error) {
+	return decodeInput("input."+string(format), data, format)
+}
+
+// DecodeInputFile reads an input file into plain Go values, detecting its
+// format from the file extension
+func DecodeInputFile(path string) (interface{}, error) {
+	format, err := FormatForFilename(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return decodeInput(path, data, format)
+}
+
+// decodeInput decodes a document into plain Go values
+func decodeInput(filename string, data []byte, format InputFormat) (interface{}, error) {
+	switch format {
+	case FormatYAML:
+		var values interface{}
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+		}
+		return values, nil
+
+	case FormatJSON:
+		var values interface{}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&values); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+		}
+		return values, nil
+
+	case FormatTFVars:
+		return decodeTFVars(filename, data)
+	}
+	return nil, fmt.Errorf("unsupported input format %q", format)
+}
+
+// decodeTFVars decodes the attributes of a .tfvars file. Like Terraform, it
+// only accepts constant values
+func decodeTFVars(filename string, data []byte) (map[string]interface{}, error) {
+	file, diags := hclsyntax.ParseConfig(data, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, diags)
+	}
+
+	attrs, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, diags)
+	}
+
+	values := make(map[string]interface{}, len(attrs))
+	for name, attr := range attrs {
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("failed to parse %s: %w", filename, diags)
+		}
+		converted, err := parser.ConvertCtyValue(val)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: variable %q: %w", filename, name, err)
+		}
+		values[name] = converted
+	}
+	return values, nil
+}
+
+// jsonPointer converts a validation context such as (root).tags.0 into a
+// JSON pointer such as /tags/0
+func jsonPointer(context *gojsonschema.JsonContext) string {
+	// A separator that cannot occur in keys keeps dotted keys intact
+	const separator = "\x00"
+
+	segments := strings.Split(context.String(separator), separator)
+	var b strings.Builder
+	for _, segment := range segments[1:] {
+		b.WriteString("/")
+		b.WriteString(escapePointer(segment))
+	}
+	return b.String()
+}
+
+// escapePointer escapes a JSON pointer reference token
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// unescapePointer reverses escapePointer
+func unescapePointer(token string) string {
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+}
+
+// schemaKeywords maps gojsonschema error types to the keyword that failed
+var schemaKeywords = map[string]string{
+	"false":                           "false",
+	"required":                        "required",
+	"invalid_type":                    "type",
+	"number_any_of":                   "anyOf",
+	"number_one_of":                   "oneOf",
+	"number_all_of":                   "allOf",
+	"number_not":                      "not",
+	"missing_dependency":              "dependencies",
+	"const":                           "const",
+	"enum":                            "enum",
+	"array_no_additional_items":       "additionalItems",
+	"array_min_items":                 "minItems",
+	"array_max_items":                 "maxItems",
+	"unique":                          "uniqueItems",
+	"contains":                        "contains",
+	"array_min_properties":            "minProperties",
+	"array_max_properties":            "maxProperties",
+	"additional_property_not_allowed": "additionalProperties",
+	"invalid_property_pattern":        "patternProperties",
+	"invalid_property_name":           "propertyNames",
+	"string_gte":                      "minLength",
+	"string_lte":                      "maxLength",
+	"pattern":                         "pattern",
+	"format":                          "format",
+	"multiple_of":                     "multipleOf",
+	"number_gte":                      "minimum",
+	"number_gt":                       "exclusiveMinimum",
+	"number_lte":                      "maximum",
+	"number_lt":                       "exclusiveMaximum",
+	"condition_then":                  "then",
+	"condition_else":                  "else",
+}
+
+// schemaKeyword returns the JSON Schema keyword for a gojsonschema error type
+func schemaKeyword(errorType string) string {
+	if keyword, ok := schemaKeywords[errorType]; ok {
+		return keyword
+	}
+	return errorType
+}
@@ -0,0 +1,273 @@
+package validator
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/samart/terraform-schema-generator/pkg/converter"
+	"github.com/samart/terraform-schema-generator/pkg/parser"
+)
+
+const inputTestConfig = `
+variable "name" {
+  type = string
+  validation {
+    condition     = can(regex("^[a-z-]+$", var.name))
+    error_message = "Use lowercase letters and dashes."
+  }
+}
+
+variable "instance_count" {
+  type    = number
+  default = 1
+  validation {
+    condition     = var.instance_count >= 1 && var.instance_count <= 10
+    error_message = "Between 1 and 10 instances."
+  }
+}
+
+variable "zones" {
+  type    = list(string)
+  default = []
+  validation {
+    condition     = alltrue([for z in var.zones : contains(["a", "b"], z)])
+    error_message = "Unknown zone."
+  }
+}
+
+variable "tags" {
+  type    = map(string)
+  default = {}
+}
+`
+
+// newTestInputValidator builds an input validator for inputTestConfig
+func newTestInputValidator(t *testing.T) *InputValidator {
+	t.Helper()
+
+	result, err := parser.NewParser().ParseFiles(map[string]io.Reader{
+		"variables.tf": strings.NewReader(inputTestConfig),
+	})
+	require.NoError(t, err)
+	schema, err := converter.NewConverter().ConvertToJSONSchema7(result)
+	require.NoError(t, err)
+
+	v, err := NewInputValidator(schema)
+	require.NoError(t, err)
+	return v
+}
+
+func TestInputValidator_Formats(t *testing.T) {
+	v := newTestInputValidator(t)
+
+	t.Run("valid YAML", func(t *testing.T) {
+		result, err := v.Validate([]byte("name: web\ninstance_count: 3\nzones: [a, b]\ntags:\n  team: core\n"), FormatYAML)
+		require.NoError(t, err)
+		assert.True(t, result.Valid)
+		assert.Empty(t, result.Errors)
+	})
+
+	t.Run("valid JSON", func(t *testing.T) {
+		result, err := v.Validate([]byte(`{"name": "web", "instance_count": 10}`), FormatJSON)
+		require.NoError(t, err)
+		assert.True(t, result.Valid)
+	})
+
+	t.Run("valid tfvars", func(t *testing.T) {
+		result, err := v.Validate([]byte(`
+name           = "web"
+instance_count = 2
+zones          = ["a"]
+tags = {
+  team = "core"
+}
+`), FormatTFVars)
+		require.NoError(t, err)
+		assert.True(t, result.Valid, "%v", result.Errors)
+	})
+
+	t.Run("explicit null for a nullable variable", func(t *testing.T) {
+		result, err := v.Validate([]byte("name: web\ntags: null\n"), FormatYAML)
+		require.NoError(t, err)
+		assert.True(t, result.Valid)
+	})
+
+	t.Run("malformed documents", func(t *testing.T) {
+		_, err := v.Validate([]byte("name: [web"), FormatYAML)
+		assert.Error(t, err)
+
+		_, err = v.Validate([]byte(`{"name": `), FormatJSON)
+		assert.Error(t, err)
+
+		_, err = v.Validate([]byte(`name = var.other`), FormatTFVars)
+		assert.Error(t, err)
+	})
+}
+
+func TestInputValidator_Errors(t *testing.T) {
+	v := newTestInputValidator(t)
+
+	t.Run("errors carry path, keyword and Terraform message", func(t *testing.T) {
+		result, err := v.Validate([]byte(`
+name: Web Server
+instance_count: 0
+zones: [a, c]
+`), FormatYAML)
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+
+		require.Len(t, result.Errors, 3)
+		assert.Equal(t, "/instance_count", result.Errors[0].Path)
+		assert.Equal(t, "minimum", result.Errors[0].Keyword)
+		assert.Equal(t, "Between 1 and 10 instances.", result.Errors[0].ErrorMessage)
+
+		assert.Equal(t, "/name", result.Errors[1].Path)
+		assert.Equal(t, "pattern", result.Errors[1].Keyword)
+		assert.Equal(t, "Use lowercase letters and dashes.", result.Errors[1].ErrorMessage)
+
+		assert.Equal(t, "/zones/1", result.Errors[2].Path)
+		assert.Equal(t, "enum", result.Errors[2].Keyword)
+		assert.Equal(t, "Unknown zone.", result.Errors[2].ErrorMessage)
+		assert.Equal(t, "/zones/1: Unknown zone. ("+result.Errors[2].Message+")", result.Errors[2].Error())
+	})
+
+	t.Run("errors without a Terraform rule", func(t *testing.T) {
+		result, err := v.Validate([]byte(`{"instance_count": "many", "tags": {"team": 1}}`), FormatJSON)
+		require.NoError(t, err)
+
+		require.Len(t, result.Errors, 3)
+		assert.Equal(t, InputError{Path: "", Keyword: "required", Message: "name is required"}, result.Errors[0])
+		assert.Equal(t, "/: name is required", result.Errors[0].Error())
+
+		assert.Equal(t, "/instance_count", result.Errors[1].Path)
+		assert.Equal(t, "type", result.Errors[1].Keyword)
+		assert.Empty(t, result.Errors[1].ErrorMessage)
+
+		assert.Equal(t, "/tags/team", result.Errors[2].Path)
+		assert.Equal(t, "type", result.Errors[2].Keyword)
+	})
+
+	t.Run("keys are escaped in paths", func(t *testing.T) {
+		result, err := v.Validate([]byte(`{"name": "web", "tags": {"a/b~c.d": 1}}`), FormatJSON)
+		require.NoError(t, err)
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, "/tags/a~1b~0c.d", result.Errors[0].Path)
+	})
+}
+
+func TestInputValidator_RuleMessages(t *testing.T) {
+	t.Run("each pattern rule reports its own message", func(t *testing.T) {
+		result, err := parser.NewParser().ParseFiles(map[string]io.Reader{
+			"variables.tf": strings.NewReader(`
+variable "name" {
+  type = string
+  validation {
+    condition     = can(regex("^[a-z-]+$", var.name))
+    error_message = "Use lowercase letters and dashes."
+  }
+  validation {
+    condition     = can(regex("^[a-z]", var.name))
+    error_message = "Start with a letter."
+  }
+}
+`),
+		})
+		require.NoError(t, err)
+		schema, err := converter.NewConverter().ConvertToJSONSchema7(result)
+		require.NoError(t, err)
+		v, err := NewInputValidator(schema)
+		require.NoError(t, err)
+
+		validation, err := v.ValidateValues(map[string]interface{}{"name": "-web"})
+		require.NoError(t, err)
+		require.Len(t, validation.Errors, 1)
+		assert.Equal(t, "pattern", validation.Errors[0].Keyword)
+		assert.Equal(t, "Start with a letter.", validation.Errors[0].ErrorMessage)
+
+		validation, err = v.ValidateValues(map[string]interface{}{"name": "web_1"})
+		require.NoError(t, err)
+		require.Len(t, validation.Errors, 1)
+		assert.Equal(t, "Use lowercase letters and dashes.", validation.Errors[0].ErrorMessage)
+	})
+
+	t.Run("nested values do not take the variable's messages", func(t *testing.T) {
+		v, err := NewInputValidatorFromJSON([]byte(`{
+  "type": "object",
+  "properties": {
+    "service": {
+      "type": "object",
+      "minProperties": 1,
+      "properties": {
+        "name": { "type": "string", "minLength": 3 }
+      },
+      "x-terraform-validations": [
+        { "condition": "length(var.service) >= 1", "error_message": "Set at least one attribute.", "translated": true, "keywords": ["minProperties"] },
+        { "condition": "length(var.service.name) >= 3", "error_message": "Unrelated rule.", "translated": false, "keywords": ["minLength"] }
+      ]
+    }
+  }
+}`))
+		require.NoError(t, err)
+
+		validation, err := v.ValidateValues(map[string]interface{}{
+			"service": map[string]interface{}{"name": "ab"},
+		})
+		require.NoError(t, err)
+		require.Len(t, validation.Errors, 1)
+		assert.Equal(t, "/service/name", validation.Errors[0].Path)
+		assert.Equal(t, "minLength", validation.Errors[0].Keyword)
+		assert.Empty(t, validation.Errors[0].ErrorMessage)
+	})
+}
+
+func TestInputValidator_ValidateFile(t *testing.T) {
+	v := newTestInputValidator(t)
+	dir := t.TempDir()
+
+	for name, content := range map[string]string{
+		"inputs.yml":         "name: web\n",
+		"inputs.tfvars":      "name = \"web\"\n",
+		"inputs.tfvars.json": `{"name": "web"}`,
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+		result, err := v.ValidateFile(path)
+		require.NoError(t, err, name)
+		assert.True(t, result.Valid, name)
+	}
+
+	t.Run("unsupported extension", func(t *testing.T) {
+		_, err := v.ValidateFile(filepath.Join(dir, "inputs.txt"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported input file")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := v.ValidateFile(filepath.Join(dir, "missing.yaml"))
+		assert.Error(t, err)
+	})
+}
+
+func TestNewInputValidatorFromJSON(t *testing.T) {
+	t.Run("any schema can be used", func(t *testing.T) {
+		v, err := NewInputValidatorFromJSON([]byte(`{"type": "object", "required": ["x"]}`))
+		require.NoError(t, err)
+
+		result, err := v.ValidateValues(map[string]interface{}{})
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+		assert.Equal(t, "required", result.Errors[0].Keyword)
+	})
+
+	t.Run("invalid schema", func(t *testing.T) {
+		_, err := NewInputValidatorFromJSON([]byte(`{"type": 1}`))
+		assert.Error(t, err)
+	})
+}
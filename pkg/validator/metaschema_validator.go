@@ -3,47 +3,62 @@ package validator
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/xeipuuv/gojsonschema"
 )
 
-// MetaSchemaValidator validates JSON schemas against the JSON Schema Draft 7 meta-schema
+// MetaSchemaValidator validates JSON schemas against a JSON Schema
+// meta-schema. By default the meta-schema of the draft named by the schema's
+// $schema keyword is used, falling back to Draft 7, and the meta-schema is
+// embedded so no network access is needed. Only Draft 7 is supported; other
+// drafts named in $schema are reported as errors
 type MetaSchemaValidator struct {
-	metaSchemaURL string
+	draft          Draft
+	metaSchemaPath string
 }
 
-// NewMetaSchemaValidator creates a new meta-schema validator
-func NewMetaSchemaValidator() *MetaSchemaValidator {
-	return &MetaSchemaValidator{
-		metaSchemaURL: "http://json-schema.org/draft-07/schema#",
+// MetaSchemaOption configures a MetaSchemaValidator
+type MetaSchemaOption func(*MetaSchemaValidator)
+
+// WithDraft validates against the embedded meta-schema of draft, whatever
+// the $schema keyword of the validated schema says. Only Draft7 has one
+func WithDraft(draft Draft) MetaSchemaOption {
+	return func(v *MetaSchemaValidator) {
+		v.draft = draft
 	}
 }
 
-// ValidateAgainstMetaSchema validates a JSON schema against the JSON Schema Draft 7 meta-schema
-func (v *MetaSchemaValidator) ValidateAgainstMetaSchema(schemaBytes []byte) error {
-	// Load the JSON Schema Draft 7 meta-schema
-	metaSchemaLoader := gojsonschema.NewReferenceLoader(v.metaSchemaURL)
-
-	// Parse the schema to validate
-	var schemaData interface{}
-	if err := json.Unmarshal(schemaBytes, &schemaData); err != nil {
-		return fmt.Errorf("failed to parse schema JSON: %w", err)
+// WithMetaSchemaFile validates against the meta-schema in a local file
+// instead of an embedded one
+func WithMetaSchemaFile(path string) MetaSchemaOption {
+	return func(v *MetaSchemaValidator) {
+		v.metaSchemaPath = path
 	}
+}
 
-	// Create a document loader from the parsed schema
-	documentLoader := gojsonschema.NewGoLoader(schemaData)
+// NewMetaSchemaValidator creates a new meta-schema validator
+func NewMetaSchemaValidator(opts ...MetaSchemaOption) *MetaSchemaValidator {
+	v := &MetaSchemaValidator{}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
 
-	// Validate the schema against the meta-schema
-	result, err := gojsonschema.Validate(metaSchemaLoader, documentLoader)
+// ValidateAgainstMetaSchema validates a JSON schema against its meta-schema
+func (v *MetaSchemaValidator) ValidateAgainstMetaSchema(schemaBytes []byte) error {
+	result, err := v.ValidateAgainstMetaSchemaWithDetails(schemaBytes)
 	if err != nil {
-		return fmt.Errorf("validation error: %w", err)
+		return err
 	}
 
 	// Check if the schema is valid
-	if !result.Valid() {
-		errMsg := "schema is not valid against JSON Schema Draft 7 meta-schema:\n"
-		for _, err := range result.Errors() {
-			errMsg += fmt.Sprintf("  - %s\n", err.String())
+	if !result.Valid {
+		errMsg := fmt.Sprintf("schema is not valid against %s meta-schema:\n", result.MetaSchema)
+		for _, err := range result.Errors {
+			errMsg += fmt.Sprintf("  - %s\n", err)
 		}
 		return fmt.Errorf("%s", errMsg)
 	}
@@ -53,23 +68,27 @@ func (v *MetaSchemaValidator) ValidateAgainstMetaSchema(schemaBytes []byte) erro
 
 // ValidateAgainstMetaSchemaWithDetails validates and returns detailed validation information
 func (v *MetaSchemaValidator) ValidateAgainstMetaSchemaWithDetails(schemaBytes []byte) (*ValidationResult, error) {
-	metaSchemaLoader := gojsonschema.NewReferenceLoader(v.metaSchemaURL)
-
+	// Parse the schema to validate
 	var schemaData interface{}
 	if err := json.Unmarshal(schemaBytes, &schemaData); err != nil {
 		return nil, fmt.Errorf("failed to parse schema JSON: %w", err)
 	}
 
-	documentLoader := gojsonschema.NewGoLoader(schemaData)
+	metaSchema, name, err := v.metaSchema(schemaData)
+	if err != nil {
+		return nil, err
+	}
 
-	result, err := gojsonschema.Validate(metaSchemaLoader, documentLoader)
+	// Validate the schema against the meta-schema
+	result, err := metaSchema.Validate(gojsonschema.NewGoLoader(schemaData))
 	if err != nil {
 		return nil, fmt.Errorf("validation error: %w", err)
 	}
 
 	validationResult := &ValidationResult{
-		Valid:  result.Valid(),
-		Errors: []string{},
+		Valid:      result.Valid(),
+		Errors:     []string{},
+		MetaSchema: name,
 	}
 
 	if !result.Valid() {
@@ -81,8 +100,49 @@ func (v *MetaSchemaValidator) ValidateAgainstMetaSchemaWithDetails(schemaBytes [
 	return validationResult, nil
 }
 
+// metaSchema compiles the meta-schema to validate schemaData against and
+// returns it with a name for messages
+func (v *MetaSchemaValidator) metaSchema(schemaData interface{}) (*gojsonschema.Schema, string, error) {
+	if v.metaSchemaPath != "" {
+		// Loading by reference resolves relative $refs next to the file
+		abs, err := filepath.Abs(v.metaSchemaPath)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to resolve meta-schema path: %w", err)
+		}
+		if _, err := os.Stat(abs); err != nil {
+			return nil, "", fmt.Errorf("failed to read meta-schema: %w", err)
+		}
+		schema, err := gojsonschema.NewSchema(gojsonschema.NewReferenceLoader("file://" + filepath.ToSlash(abs)))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to compile meta-schema %s: %w", v.metaSchemaPath, err)
+		}
+		return schema, v.metaSchemaPath, nil
+	}
+
+	draft := v.draft
+	if draft == "" {
+		draft = Draft7
+		if object, ok := schemaData.(map[string]interface{}); ok {
+			if uri, ok := object["$schema"].(string); ok {
+				if declared, ok := draftForURI(uri); ok {
+					draft = declared
+				}
+			}
+		}
+	}
+
+	schema, err := compileEmbeddedMetaSchema(draft)
+	if err != nil {
+		return nil, "", err
+	}
+	return schema, draft.String(), nil
+}
+
 // ValidationResult represents the result of a meta-schema validation
 type ValidationResult struct {
 	Valid  bool
 	Errors []string
+
+	// MetaSchema names the meta-schema the schema was checked against
+	MetaSchema string
 }
@@ -2,6 +2,8 @@ package validator
 
 import (
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -307,3 +309,90 @@ func TestMetaSchemaValidator_RealWorldSchemas(t *testing.T) {
 		assert.NoError(t, err, "Nested structure schema should be valid")
 	})
 }
+
+func TestMetaSchemaValidator_Drafts(t *testing.T) {
+	t.Run("embedded meta-schema compiles", func(t *testing.T) {
+		_, err := compileEmbeddedMetaSchema(Draft7)
+		assert.NoError(t, err)
+	})
+
+	t.Run("draft chosen from $schema", func(t *testing.T) {
+		cases := []string{
+			`{"type": "object"}`,
+			`{"$schema": "http://json-schema.org/draft-07/schema#", "type": "object"}`,
+			`{"$schema": "https://json-schema.org/draft-07/schema", "type": "object"}`,
+		}
+		for _, schema := range cases {
+			result, err := NewMetaSchemaValidator().ValidateAgainstMetaSchemaWithDetails([]byte(schema))
+			require.NoError(t, err, schema)
+			assert.True(t, result.Valid, schema)
+			assert.Equal(t, "JSON Schema Draft 7", result.MetaSchema, schema)
+		}
+	})
+
+	t.Run("newer drafts are refused rather than partly checked", func(t *testing.T) {
+		for _, draft := range []Draft{Draft201909, Draft202012} {
+			for _, schema := range []string{
+				`{"$schema": "` + string(draft) + `", "type": "object"}`,
+				`{"$schema": "` + string(draft) + `", "type": "object", "minProperties": -1}`,
+				// Nested keywords are only reachable through $dynamicRef
+				`{"$schema": "` + string(draft) + `", "properties": {"a": {"type": 5}}}`,
+			} {
+				err := NewMetaSchemaValidator().ValidateAgainstMetaSchema([]byte(schema))
+				require.Error(t, err, schema)
+				assert.Contains(t, err.Error(), draft.String()+" schemas cannot be validated", schema)
+			}
+
+			err := NewMetaSchemaValidator(WithDraft(draft)).ValidateAgainstMetaSchema([]byte(`{"type": "object"}`))
+			assert.Error(t, err, draft.String())
+		}
+	})
+
+	t.Run("explicit draft overrides $schema", func(t *testing.T) {
+		// $defs is not a Draft 7 keyword, so it is not checked there
+		schema := []byte(`{"$schema": "https://json-schema.org/draft/2020-12/schema", "$defs": 1}`)
+
+		assert.Error(t, NewMetaSchemaValidator().ValidateAgainstMetaSchema(schema))
+		assert.NoError(t, NewMetaSchemaValidator(WithDraft(Draft7)).ValidateAgainstMetaSchema(schema))
+	})
+}
+
+func TestMetaSchemaValidator_WithMetaSchemaFile(t *testing.T) {
+	dir := t.TempDir()
+
+	// A stricter house style: every schema needs a title
+	metaSchema := filepath.Join(dir, "meta.json")
+	require.NoError(t, os.WriteFile(metaSchema, []byte(`{
+		"allOf": [{"$ref": "base.json"}],
+		"required": ["title"]
+	}`), 0644))
+	base, err := metaSchemas.ReadFile("metaschemas/draft-07/schema.json")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "base.json"), base, 0644))
+
+	v := NewMetaSchemaValidator(WithMetaSchemaFile(metaSchema))
+
+	t.Run("schema meeting the custom meta-schema", func(t *testing.T) {
+		result, err := v.ValidateAgainstMetaSchemaWithDetails([]byte(`{"title": "Inputs", "type": "object"}`))
+		require.NoError(t, err)
+		assert.True(t, result.Valid)
+		assert.Equal(t, metaSchema, result.MetaSchema)
+	})
+
+	t.Run("schema violating the custom meta-schema", func(t *testing.T) {
+		err := v.ValidateAgainstMetaSchema([]byte(`{"type": "object"}`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "title")
+
+		// Relative references are resolved next to the file
+		err = v.ValidateAgainstMetaSchema([]byte(`{"title": "Inputs", "type": "strin"}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("missing meta-schema file", func(t *testing.T) {
+		err := NewMetaSchemaValidator(WithMetaSchemaFile(filepath.Join(dir, "missing.json"))).
+			ValidateAgainstMetaSchema([]byte(`{"type": "object"}`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read meta-schema")
+	})
+}
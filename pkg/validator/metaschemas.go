@@ -0,0 +1,92 @@
+package validator
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// metaSchemas holds copies of the official JSON Schema meta-schemas, so that
+// schemas can be checked without network access
+//
+//go:embed metaschemas
+var metaSchemas embed.FS
+
+// Draft identifies a JSON Schema specification by its meta-schema URI
+type Draft string
+
+const (
+	// Draft7 is JSON Schema draft-07, which the converter generates
+	Draft7 Draft = "http://json-schema.org/draft-07/schema#"
+
+	// Draft201909 is JSON Schema 2019-09
+	Draft201909 Draft = "https://json-schema.org/draft/2019-09/schema"
+
+	// Draft202012 is JSON Schema 2020-12
+	Draft202012 Draft = "https://json-schema.org/draft/2020-12/schema"
+)
+
+// draftDirs maps each draft that can be validated to its directory of
+// embedded meta-schemas
+var draftDirs = map[Draft]string{
+	Draft7: "metaschemas/draft-07",
+}
+
+// knownDrafts lists the drafts recognised in $schema. The 2019-09 and
+// 2020-12 meta-schemas check nested subschemas through $recursiveRef and
+// $dynamicRef, which gojsonschema ignores, so schemas of those drafts are
+// refused rather than only checked at the top level
+var knownDrafts = []Draft{Draft7, Draft201909, Draft202012}
+
+// String returns the name of the draft as used in messages
+func (d Draft) String() string {
+	switch d {
+	case Draft7:
+		return "JSON Schema Draft 7"
+	case Draft201909:
+		return "JSON Schema 2019-09"
+	case Draft202012:
+		return "JSON Schema 2020-12"
+	}
+	return string(d)
+}
+
+// draftForURI returns the draft a $schema URI refers to. URIs are compared
+// without a trailing empty fragment or scheme differences, as both forms are
+// used in the wild
+func draftForURI(uri string) (Draft, bool) {
+	normalize := func(s string) string {
+		s = strings.TrimSuffix(s, "#")
+		s = strings.TrimPrefix(s, "https://")
+		return strings.TrimPrefix(s, "http://")
+	}
+	for _, draft := range knownDrafts {
+		if normalize(string(draft)) == normalize(uri) {
+			return draft, true
+		}
+	}
+	return "", false
+}
+
+// compileEmbeddedMetaSchema compiles the embedded meta-schema of a draft
+func compileEmbeddedMetaSchema(draft Draft) (*gojsonschema.Schema, error) {
+	dir, ok := draftDirs[draft]
+	if !ok {
+		return nil, fmt.Errorf("%s schemas cannot be validated: their meta-schema relies on $recursiveRef or $dynamicRef, which is not supported, so nested subschemas would go unchecked", draft)
+	}
+
+	data, err := metaSchemas.ReadFile(path.Join(dir, "schema.json"))
+	if err != nil {
+		return nil, err
+	}
+	loader := gojsonschema.NewSchemaLoader()
+	loader.Draft = gojsonschema.Draft7
+	schema, err := loader.Compile(gojsonschema.NewBytesLoader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile %s meta-schema: %w", draft, err)
+	}
+	return schema, nil
+}
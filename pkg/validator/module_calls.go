@@ -0,0 +1,196 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/samart/terraform-schema-generator/pkg/converter"
+	"github.com/samart/terraform-schema-generator/pkg/parser"
+)
+
+// ValidateModuleCalls checks the arguments of every module block in the tree
+// below result whose module has been loaded, see parser.LoadModules, against
+// the variables that module declares. It reports required variables without
+// an argument, arguments that set no variable, and literal values that do
+// not convert to their variable's type or that violate the allowed values
+// or bounds of its schema. Values computed from references or function
+// calls are only checked for presence
+func ValidateModuleCalls(result *parser.ParseResult) (parser.Diagnostics, error) {
+	var diags parser.Diagnostics
+	var err error
+	result.WalkModules(func(key string, module *parser.Module) {
+		if err != nil || module.Config == nil {
+			return
+		}
+		var callDiags parser.Diagnostics
+		callDiags, err = validateModuleCall(key, module)
+		diags = append(diags, callDiags...)
+	})
+	return diags, err
+}
+
+// validateModuleCall checks the arguments of a single module block
+func validateModuleCall(key string, module *parser.Module) (parser.Diagnostics, error) {
+	var diags parser.Diagnostics
+	address := "module." + strings.ReplaceAll(key, ".", ".module.")
+
+	declared := make(map[string]parser.Variable, len(module.Config.Variables))
+	for _, variable := range module.Config.Variables {
+		declared[variable.Name] = variable
+	}
+
+	arguments := make(map[string]parser.ModuleArgument, len(module.Arguments))
+	literals := make(map[string]interface{})
+	var checked []parser.Variable
+
+	for _, argument := range module.Arguments {
+		arguments[argument.Name] = argument
+		variable, ok := declared[argument.Name]
+		if !ok {
+			diags = append(diags, moduleCallError(argument.Range, "Unsupported argument",
+				fmt.Sprintf("An argument named %q is not expected here: %s declares no variable %q.", argument.Name, address, argument.Name)))
+			continue
+		}
+		if !argument.IsLiteral() {
+			continue
+		}
+
+		value, problem := literalArgument(variable, argument)
+		if problem != "" {
+			diags = append(diags, moduleCallError(argument.Range, "Invalid value for input variable",
+				fmt.Sprintf("The given value is not suitable for %s.var.%s: %s.", address, variable.Name, problem)))
+			continue
+		}
+		if value != nil {
+			literals[variable.Name] = value
+			checked = append(checked, variable)
+		}
+	}
+
+	for _, variable := range module.Config.Variables {
+		if _, ok := arguments[variable.Name]; ok || !variable.Required {
+			continue
+		}
+		start := module.Range.Start
+		diags = append(diags, moduleCallError(parser.Range{Filename: module.Range.Filename, Start: start, End: start}, "Missing required argument",
+			fmt.Sprintf("The argument %q is required by %s, but no definition was found.", variable.Name, address)))
+	}
+
+	if len(checked) == 0 {
+		return diags, nil
+	}
+
+	// Check the literal values against the schema of their variables for
+	// the allowed values and bounds derived from validation rules
+	schema, err := converter.NewConverter().ConvertToJSONSchema7(&parser.ParseResult{Variables: checked})
+	if err != nil {
+		return diags, fmt.Errorf("failed to convert variables of %s: %w", address, err)
+	}
+	v, err := NewInputValidator(schema)
+	if err != nil {
+		return diags, fmt.Errorf("failed to validate arguments of %s: %w", address, err)
+	}
+	validation, err := v.ValidateValues(literals)
+	if err != nil {
+		return diags, fmt.Errorf("failed to validate arguments of %s: %w", address, err)
+	}
+	for _, inputErr := range validation.Errors {
+		name := pointerRoot(inputErr.Path)
+		argument, ok := arguments[name]
+		if !ok {
+			continue
+		}
+		problem := inputErr.Message
+		if inputErr.ErrorMessage != "" {
+			problem = fmt.Sprintf("%s (%s)", inputErr.ErrorMessage, inputErr.Message)
+		}
+		if nested := strings.TrimPrefix(inputErr.Path, "/"+escapePointer(name)); nested != "" {
+			problem = fmt.Sprintf("%s: %s", nested, problem)
+		}
+		diags = append(diags, moduleCallError(argument.Range, "Invalid value for input variable",
+			fmt.Sprintf("The given value is not suitable for %s.var.%s: %s.", address, name, strings.TrimSuffix(problem, "."))))
+	}
+
+	// Report problems in the order they appear in the module block
+	sort.SliceStable(diags, func(i, j int) bool {
+		return diags[i].Subject != nil && diags[j].Subject != nil && diags[i].Subject.Start.Byte < diags[j].Subject.Start.Byte
+	})
+	return diags, nil
+}
+
+// literalArgument converts a literal argument to its variable's type and
+// returns it as a plain Go value, or describes why it cannot be converted.
+// Null values, which leave the variable at its default, yield neither
+func literalArgument(variable parser.Variable, argument parser.ModuleArgument) (interface{}, string) {
+	if argument.Value.IsNull() {
+		if !variable.Nullable && variable.Required {
+			return nil, "the variable is not nullable and has no default"
+		}
+		return nil, ""
+	}
+
+	val := argument.Value
+	spec := variable.TypeSpec
+	if spec == nil && variable.Type != "" {
+		spec, _ = parser.ParseTypeString(variable.Type)
+	}
+	if spec != nil {
+		var err error
+		if val, err = parser.ConformValue(val, spec); err != nil {
+			return nil, conversionProblem(err)
+		}
+	}
+
+	value, err := parser.ConvertCtyValue(val)
+	if err != nil {
+		return nil, err.Error()
+	}
+	return value, ""
+}
+
+// conversionProblem describes a type conversion error, naming the element
+// or attribute it occurred at the way Terraform does
+func conversionProblem(err error) string {
+	var pathErr cty.PathError
+	if !errors.As(err, &pathErr) {
+		return err.Error()
+	}
+
+	parts := make([]string, 0, len(pathErr.Path)+1)
+	for _, step := range pathErr.Path {
+		switch step := step.(type) {
+		case cty.GetAttrStep:
+			parts = append(parts, fmt.Sprintf("attribute %q", step.Name))
+		case cty.IndexStep:
+			if step.Key.Type() == cty.String {
+				parts = append(parts, fmt.Sprintf("element %q", step.Key.AsString()))
+			} else if step.Key.Type() == cty.Number {
+				parts = append(parts, fmt.Sprintf("element %s", step.Key.AsBigFloat().Text('f', -1)))
+			}
+		}
+	}
+	return strings.Join(append(parts, pathErr.Error()), ": ")
+}
+
+// moduleCallError creates an error diagnostic about part of a module block
+func moduleCallError(rng parser.Range, summary, detail string) parser.Diagnostic {
+	diag := parser.Diagnostic{
+		Severity: parser.SeverityError,
+		Summary:  summary,
+		Detail:   detail,
+	}
+	if rng.Filename != "" {
+		diag.Subject = &rng
+	}
+	return diag
+}
+
+// pointerRoot returns the first, unescaped, segment of a JSON pointer
+func pointerRoot(pointer string) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(pointer, "/"), "/")
+	return unescapePointer(segment)
+}
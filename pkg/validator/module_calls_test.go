@@ -0,0 +1,184 @@
+package validator
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/samart/terraform-schema-generator/pkg/parser"
+)
+
+const moduleCallsChildConfig = `
+variable "cidr" {
+  type = string
+}
+
+variable "environment" {
+  type    = string
+  default = "dev"
+  validation {
+    condition     = contains(["dev", "prod"], var.environment)
+    error_message = "Unknown environment."
+  }
+}
+
+variable "azs" {
+  type    = number
+  default = 2
+  validation {
+    condition     = var.azs >= 1 && var.azs <= 3
+    error_message = "Between 1 and 3 availability zones."
+  }
+}
+
+variable "subnets" {
+  type = list(object({
+    name = string
+    size = number
+  }))
+  default = []
+}
+
+variable "name" {
+  type     = string
+  nullable = false
+}
+
+variable "logging" {
+  type = object({
+    enabled = bool
+    bucket  = optional(string)
+    prefix  = optional(string, "logs")
+    targets = optional(list(object({
+      arn    = string
+      format = optional(string)
+    })))
+  })
+  default = { enabled = false }
+}
+`
+
+// parseModuleCall parses a root configuration and attaches the child
+// configuration to its first module block, as LoadModules would
+func parseModuleCall(t *testing.T, root string) *parser.ParseResult {
+	t.Helper()
+
+	result, err := parser.NewParser().ParseFiles(map[string]io.Reader{
+		"main.tf": strings.NewReader(root),
+	})
+	require.NoError(t, err)
+	child, err := parser.NewParser().ParseFiles(map[string]io.Reader{
+		"modules/network/variables.tf": strings.NewReader(moduleCallsChildConfig),
+	})
+	require.NoError(t, err)
+
+	require.NotEmpty(t, result.Modules)
+	result.Modules[0].Config = child
+	return result
+}
+
+func TestValidateModuleCalls(t *testing.T) {
+	t.Run("valid call", func(t *testing.T) {
+		result := parseModuleCall(t, `
+module "network" {
+  source      = "./modules/network"
+  cidr        = "10.0.0.0/16"
+  environment = "prod"
+  azs         = "3"
+  name        = "${var.prefix}-network"
+  subnets     = [{ name = "a", size = 24 }]
+}
+`)
+		diags, err := ValidateModuleCalls(result)
+		require.NoError(t, err)
+		assert.Empty(t, diags)
+	})
+
+	t.Run("optional attributes may be omitted or null", func(t *testing.T) {
+		result := parseModuleCall(t, `
+module "network" {
+  source  = "./modules/network"
+  cidr    = "10.0.0.0/16"
+  name    = "core"
+  logging = {
+    enabled = true
+    bucket  = null
+    targets = [{ arn = "arn:aws:s3:::logs" }]
+  }
+}
+`)
+		diags, err := ValidateModuleCalls(result)
+		require.NoError(t, err)
+		assert.Empty(t, diags)
+	})
+
+	t.Run("reports every problem with its range", func(t *testing.T) {
+		result := parseModuleCall(t, `
+module "network" {
+  source      = "./modules/network"
+  environment = "staging"
+  azs         = 5
+  subnets     = [{ name = "a", size = "large" }]
+  zones       = ["a"]
+  name        = null
+}
+`)
+		diags, err := ValidateModuleCalls(result)
+		require.NoError(t, err)
+
+		messages := make([]string, len(diags))
+		for i, diag := range diags {
+			assert.Equal(t, parser.SeverityError, diag.Severity)
+			require.NotNil(t, diag.Subject)
+			messages[i] = diag.Error()
+		}
+
+		assert.Equal(t, []string{
+			`main.tf:2,1-2,1: Missing required argument; The argument "cidr" is required by module.network, but no definition was found.`,
+			`main.tf:4,3-4,26: Invalid value for input variable; The given value is not suitable for module.network.var.environment: Unknown environment. (environment must be one of the following: "dev", "prod").`,
+			`main.tf:5,3-5,18: Invalid value for input variable; The given value is not suitable for module.network.var.azs: Between 1 and 3 availability zones. (Must be less than or equal to 3).`,
+			`main.tf:6,3-6,49: Invalid value for input variable; The given value is not suitable for module.network.var.subnets: element 0: attribute "size": a number is required.`,
+			`main.tf:7,3-7,22: Unsupported argument; An argument named "zones" is not expected here: module.network declares no variable "zones".`,
+			`main.tf:8,3-8,21: Invalid value for input variable; The given value is not suitable for module.network.var.name: the variable is not nullable and has no default.`,
+		}, messages)
+	})
+
+	t.Run("nested modules use their full address", func(t *testing.T) {
+		result := parseModuleCall(t, `
+module "network" {
+  source = "./modules/network"
+  cidr   = "10.0.0.0/16"
+  name   = "core"
+}
+`)
+		child := result.Modules[0].Config
+		child.Modules = []parser.Module{{
+			Name:      "subnets",
+			Source:    "./subnets",
+			Arguments: []parser.ModuleArgument{{Name: "size", Expression: "var.size"}},
+			Config:    &parser.ParseResult{},
+		}}
+
+		diags, err := ValidateModuleCalls(result)
+		require.NoError(t, err)
+		require.Len(t, diags, 1)
+		assert.Contains(t, diags[0].Detail, "module.network.module.subnets declares no variable")
+		assert.Nil(t, diags[0].Subject)
+	})
+
+	t.Run("modules that were not loaded are skipped", func(t *testing.T) {
+		result := parseModuleCall(t, `
+module "network" {
+  source = "./modules/network"
+}
+`)
+		result.Modules[0].Config = nil
+
+		diags, err := ValidateModuleCalls(result)
+		require.NoError(t, err)
+		assert.Empty(t, diags)
+	})
+}